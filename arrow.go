@@ -0,0 +1,217 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// arrowBatchSize is the number of rows in each arrow record written to a file
+const arrowBatchSize = 10000
+
+// timeLayouts are the layouts of the time values drivers return as text
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+
+// recordBuilder collects rows into arrow records with a schema mapped from the column types
+type recordBuilder struct {
+	columns []*ResultColumn
+	schema  *arrow.Schema
+	builder *array.RecordBuilder
+	rows    int
+}
+
+func newRecordBuilder(columns []*sql.ColumnType) *recordBuilder {
+	fields := make([]arrow.Field, len(columns))
+	for i, column := range columns {
+		fields[i] = arrow.Field{Name: column.Name(), Type: arrowType(column), Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	return &recordBuilder{
+		columns: resultColumns(columns),
+		schema:  schema,
+		builder: array.NewRecordBuilder(memory.DefaultAllocator, schema),
+	}
+}
+
+// arrowType maps the type a database reports for a column to an arrow type, values without a better match are strings
+func arrowType(column *sql.ColumnType) arrow.DataType {
+	switch databaseKind(column.DatabaseTypeName()) {
+	case "integer":
+		// mysql reports unsigned columns with an UNSIGNED prefix, an unsigned bigint can exceed the range of int64
+		if strings.EqualFold(column.DatabaseTypeName(), "UNSIGNED BIGINT") {
+			return arrow.PrimitiveTypes.Uint64
+		}
+		return arrow.PrimitiveTypes.Int64
+	case "float":
+		return arrow.PrimitiveTypes.Float64
+	case "decimal":
+		if precision, scale, ok := column.DecimalSize(); ok && precision > 0 && precision <= 38 && scale >= 0 && scale <= precision {
+			return &arrow.Decimal128Type{Precision: int32(precision), Scale: int32(scale)}
+		}
+	case "boolean":
+		return arrow.FixedWidthTypes.Boolean
+	case "time":
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	case "bytes":
+		return arrow.BinaryTypes.Binary
+	}
+
+	return arrow.BinaryTypes.String
+}
+
+// append adds a row to the record being built
+func (r *recordBuilder) append(values []any) error {
+	for i, value := range values {
+		if err := r.appendValue(i, value); err != nil {
+			return fmt.Errorf("error converting %s: %w", r.columns[i].Name, err)
+		}
+	}
+	r.rows++
+
+	return nil
+}
+
+func (r *recordBuilder) appendValue(i int, value any) error {
+	field := r.builder.Field(i)
+	if value == nil {
+		field.AppendNull()
+		return nil
+	}
+	cell := typedCell(value, r.columns[i].DatabaseType)
+
+	switch b := field.(type) {
+	case *array.Int64Builder:
+		n, err := strconv.ParseInt(cell.Value, 10, 64)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.Uint64Builder:
+		n, err := strconv.ParseUint(cell.Value, 10, 64)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.Float64Builder:
+		n, err := strconv.ParseFloat(cell.Value, 64)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.Decimal128Builder:
+		t := b.Type().(*arrow.Decimal128Type)
+		n, err := decimal128.FromString(cell.Value, t.Precision, t.Scale)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.BooleanBuilder:
+		v, err := strconv.ParseBool(cell.Value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.TimestampBuilder:
+		t, ok := value.(time.Time)
+		if !ok {
+			var err error
+			if t, err = parseTime(cell.Value); err != nil {
+				return err
+			}
+		}
+		ts, err := arrow.TimestampFromTime(t.UTC(), arrow.Microsecond)
+		if err != nil {
+			return err
+		}
+		b.Append(ts)
+	case *array.BinaryBuilder:
+		if v, ok := value.([]byte); ok {
+			b.Append(v)
+		} else {
+			b.Append([]byte(cell.Value))
+		}
+	case *array.StringBuilder:
+		b.Append(cell.Value)
+	}
+
+	return nil
+}
+
+// parseTime parses a time a driver returned as text
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported time %q", value)
+}
+
+// record returns the rows appended since the last record and starts a new one, the caller releases it
+func (r *recordBuilder) record() arrow.Record {
+	r.rows = 0
+	return r.builder.NewRecord()
+}
+
+func (r *recordBuilder) release() {
+	r.builder.Release()
+}
+
+// arrowWriter writes the rows as an arrow IPC stream in records of arrowBatchSize rows
+type arrowWriter struct {
+	w       io.Writer
+	records *recordBuilder
+	stream  *ipc.Writer
+}
+
+func newArrowWriter(w io.Writer, _ formatOptions) rowWriter {
+	return &arrowWriter{w: w}
+}
+
+func (a *arrowWriter) header(columns []*sql.ColumnType) error {
+	a.records = newRecordBuilder(columns)
+	a.stream = ipc.NewWriter(a.w, ipc.WithSchema(a.records.schema))
+	return nil
+}
+
+func (a *arrowWriter) row(values []any) error {
+	if err := a.records.append(values); err != nil {
+		return err
+	}
+	if a.records.rows < arrowBatchSize {
+		return nil
+	}
+
+	return a.write()
+}
+
+func (a *arrowWriter) write() error {
+	record := a.records.record()
+	defer record.Release()
+
+	return a.stream.Write(record)
+}
+
+func (a *arrowWriter) flush() error {
+	defer a.records.release()
+
+	if a.records.rows > 0 {
+		if err := a.write(); err != nil {
+			return err
+		}
+	}
+
+	// closing the stream writes its end-of-stream marker
+	return a.stream.Close()
+}
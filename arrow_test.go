@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+)
+
+func TestArrowWriterUnsignedBigint(t *testing.T) {
+	db := sql.OpenDB(&rowsDriver{
+		columns: []string{"id", "n"},
+		types:   []string{"UNSIGNED BIGINT", "UNSIGNED INT"},
+		rows: [][]driver.Value{
+			// the text protocol of mysql returns numbers as bytes
+			{[]byte("18446744073709551615"), []byte("4294967295")},
+			{uint64(1), int64(2)},
+		},
+	})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, n FROM counters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var b bytes.Buffer
+	w := newArrowWriter(&b, formatOptions{})
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.header(columns); err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		values, err := scanRow(rows, len(columns))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.row(values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := ipc.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	if got := r.Schema().Field(0).Type; !arrow.TypeEqual(got, arrow.PrimitiveTypes.Uint64) {
+		t.Errorf("UNSIGNED BIGINT is written as %s, want uint64", got)
+	}
+	if got := r.Schema().Field(1).Type; !arrow.TypeEqual(got, arrow.PrimitiveTypes.Int64) {
+		t.Errorf("UNSIGNED INT is written as %s, want int64", got)
+	}
+
+	if !r.Next() {
+		t.Fatalf("no record: %v", r.Err())
+	}
+	ids := r.Record().Column(0).(*array.Uint64)
+	if ids.Len() != 2 || ids.Value(0) != 18446744073709551615 || ids.Value(1) != 1 {
+		t.Errorf("ids = %v, want [18446744073709551615 1]", ids)
+	}
+}
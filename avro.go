@@ -0,0 +1,162 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hamba/avro/v2/ocf"
+)
+
+// avroInvalid matches the characters that avro names cannot contain
+var avroInvalid = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// avroWriter writes the rows as an avro object container file with a schema derived from the column types
+type avroWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	fields  []string
+	types   []string
+	encoder *ocf.Encoder
+}
+
+func newAvroWriter(w io.Writer, _ formatOptions) rowWriter {
+	return &avroWriter{w: w}
+}
+
+func (a *avroWriter) header(columns []*sql.ColumnType) error {
+	a.columns = resultColumns(columns)
+	a.fields = avroNames(columnNames(columns))
+
+	fields := make([]map[string]any, len(columns))
+	for i, column := range columns {
+		avroType, kind := avroType(column)
+		a.types = append(a.types, kind)
+		fields[i] = map[string]any{
+			"name":    a.fields[i],
+			"type":    []any{"null", avroType},
+			"default": nil,
+		}
+		if a.fields[i] != column.Name() {
+			fields[i]["doc"] = column.Name()
+		}
+	}
+
+	schema, err := json.Marshal(map[string]any{"type": "record", "name": "Row", "fields": fields})
+	if err != nil {
+		return err
+	}
+
+	encoder, err := ocf.NewEncoder(string(schema), a.w, ocf.WithCodec(ocf.Deflate))
+	if err != nil {
+		return fmt.Errorf("error creating avro schema: %w", err)
+	}
+	a.encoder = encoder
+
+	return nil
+}
+
+// avroType maps the type a database reports for a column to an avro type and the kind of the values it holds, values
+// without a better match are strings
+func avroType(column *sql.ColumnType) (any, string) {
+	switch kind := databaseKind(column.DatabaseTypeName()); kind {
+	case "integer":
+		// avro has no unsigned long, an unsigned bigint can exceed the range of long so it is written as a decimal
+		if strings.EqualFold(column.DatabaseTypeName(), "UNSIGNED BIGINT") {
+			return map[string]any{"type": "bytes", "logicalType": "decimal", "precision": 20, "scale": 0}, "decimal"
+		}
+		return "long", kind
+	case "float":
+		return "double", kind
+	case "decimal":
+		if precision, scale, ok := column.DecimalSize(); ok && precision > 0 && scale >= 0 && scale <= precision {
+			return map[string]any{"type": "bytes", "logicalType": "decimal", "precision": precision, "scale": scale}, kind
+		}
+	case "boolean":
+		return "boolean", kind
+	case "time":
+		return map[string]any{"type": "long", "logicalType": "timestamp-micros"}, kind
+	case "bytes":
+		return "bytes", kind
+	}
+
+	return "string", "string"
+}
+
+// avroNames turns column names into unique avro field names
+func avroNames(names []string) []string {
+	fields := make([]string, len(names))
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		field := avroInvalid.ReplaceAllString(name, "_")
+		if field == "" || (field[0] >= '0' && field[0] <= '9') {
+			field = "_" + field
+		}
+		for n := 2; seen[field]; n++ {
+			field = fmt.Sprintf("%s_%d", avroInvalid.ReplaceAllString(name, "_"), n)
+		}
+		seen[field] = true
+		fields[i] = field
+	}
+
+	return fields
+}
+
+func (a *avroWriter) row(values []any) error {
+	record := make(map[string]any, len(values))
+	for i, value := range values {
+		v, err := a.value(i, value)
+		if err != nil {
+			return fmt.Errorf("error converting %s: %w", a.columns[i].Name, err)
+		}
+		record[a.fields[i]] = v
+	}
+
+	return a.encoder.Encode(record)
+}
+
+// value converts a scanned value into the go type the avro encoder writes for the type of its column
+func (a *avroWriter) value(i int, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	cell := typedCell(value, a.columns[i].DatabaseType)
+
+	switch a.types[i] {
+	case "integer":
+		return strconv.ParseInt(cell.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(cell.Value, 64)
+	case "decimal":
+		n, ok := new(big.Rat).SetString(cell.Value)
+		if !ok {
+			return nil, fmt.Errorf("invalid decimal %q", cell.Value)
+		}
+		return n, nil
+	case "boolean":
+		return strconv.ParseBool(cell.Value)
+	case "time":
+		if t, ok := value.(time.Time); ok {
+			return t.UTC(), nil
+		}
+		t, err := parseTime(cell.Value)
+		return t.UTC(), err
+	case "bytes":
+		if b, ok := value.([]byte); ok {
+			return b, nil
+		}
+		return []byte(cell.Value), nil
+	}
+
+	return cell.Value, nil
+}
+
+func (a *avroWriter) flush() error {
+	return a.encoder.Close()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+
+	"github.com/hamba/avro/v2/ocf"
+)
+
+func TestAvroWriterUnsignedBigint(t *testing.T) {
+	db := sql.OpenDB(&rowsDriver{
+		columns: []string{"id", "n"},
+		types:   []string{"UNSIGNED BIGINT", "UNSIGNED INT"},
+		rows: [][]driver.Value{
+			{[]byte("18446744073709551615"), []byte("4294967295")},
+			{uint64(1), int64(2)},
+		},
+	})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, n FROM counters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var b bytes.Buffer
+	w := newAvroWriter(&b, formatOptions{})
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.header(columns); err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		values, err := scanRow(rows, len(columns))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.row(values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	dec, err := ocf.NewDecoder(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for dec.HasNext() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, record["id"].(*big.Rat).FloatString(0))
+	}
+	if err := dec.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got[0] != "18446744073709551615" || got[1] != "1" {
+		t.Errorf("ids = %v, want [18446744073709551615 1]", got)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+)
+
+// awsCredentials matches the JSON printed by `aws configure export-credentials --format process`
+type awsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+}
+
+// Authenticate to Amazon RDS with short-lived IAM auth tokens instead of a password (postgres and mysql only)
+func (m *Sql) WithAWSIAMAuth(
+	// AWS region of the database, e.g. us-east-1
+	region string,
+	// Credentials in the JSON format of `aws configure export-credentials --format process`
+	credentials *dagger.Secret,
+) *Sql {
+	m.AWSRegion = region
+	m.AWSCredentials = credentials
+	return m
+}
+
+// rdsAuthToken generates an IAM auth token for a user on the database endpoint (host:port)
+func (m *Sql) rdsAuthToken(ctx context.Context, endpoint, user string) (string, error) {
+	plaintext, err := m.AWSCredentials.Plaintext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting plaintext AWS credentials: %w", err)
+	}
+
+	var creds awsCredentials
+	if err := json.Unmarshal([]byte(plaintext), &creds); err != nil {
+		return "", fmt.Errorf("error parsing AWS credentials: %w", err)
+	}
+
+	provider := credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken)
+	token, err := auth.BuildAuthToken(ctx, endpoint, m.AWSRegion, user, provider)
+	if err != nil {
+		return "", fmt.Errorf("error building RDS auth token: %w", err)
+	}
+
+	return token, nil
+}
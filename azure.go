@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// azureDatabaseScope is the token scope for Azure Database for PostgreSQL and MySQL
+const azureDatabaseScope = "https://ossrdbms-aad.database.windows.net/.default"
+
+// Authenticate to Azure Database for PostgreSQL or MySQL with Entra ID tokens instead of a password
+func (m *Sql) WithAzureAD(
+	// Directory (tenant) ID
+	tenant string,
+	// Application (client) ID of the service principal
+	clientID string,
+	// Client secret of the service principal
+	clientSecret *dagger.Secret,
+) *Sql {
+	m.AzureTenant = tenant
+	m.AzureClientID = clientID
+	m.AzureClientSecret = clientSecret
+	return m
+}
+
+// azureToken acquires an Entra ID access token for the database
+func (m *Sql) azureToken(ctx context.Context) (string, error) {
+	secret, err := m.AzureClientSecret.Plaintext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting plaintext client secret: %w", err)
+	}
+
+	cred, err := azidentity.NewClientSecretCredential(m.AzureTenant, m.AzureClientID, secret, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating Azure credential: %w", err)
+	}
+
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureDatabaseScope}})
+	if err != nil {
+		return "", fmt.Errorf("error acquiring Azure token: %w", err)
+	}
+
+	return token.Token, nil
+}
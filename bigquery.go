@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// bigqueryConnector adapts the BigQuery client to database/sql so it can be used like any other driver
+type bigqueryConnector struct {
+	project     string
+	dataset     string
+	credentials []byte
+}
+
+func (c *bigqueryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var opts []option.ClientOption
+	if len(c.credentials) > 0 {
+		opts = append(opts, option.WithCredentialsJSON(c.credentials))
+	}
+
+	client, err := bigquery.NewClient(ctx, c.project, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bigquery client: %w", err)
+	}
+
+	return &bigqueryConn{client: client, dataset: c.dataset}, nil
+}
+
+func (c *bigqueryConnector) Driver() driver.Driver { return bigqueryDriver{} }
+
+type bigqueryDriver struct{}
+
+func (bigqueryDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("bigquery: connections must be opened with a connector")
+}
+
+type bigqueryConn struct {
+	client  *bigquery.Client
+	dataset string
+}
+
+func (c *bigqueryConn) query(query string, args []driver.NamedValue) *bigquery.Query {
+	q := c.client.Query(query)
+	q.DefaultDatasetID = c.dataset
+	for _, arg := range args {
+		q.Parameters = append(q.Parameters, bigquery.QueryParameter{Name: arg.Name, Value: arg.Value})
+	}
+
+	return q
+}
+
+func (c *bigqueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	it, err := c.query(query, args).Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the schema is only guaranteed to be populated after the first call to Next
+	rows := &bigqueryRows{it: it}
+	var first []bigquery.Value
+	switch err := it.Next(&first); {
+	case errors.Is(err, iterator.Done):
+		rows.done = true
+	case err != nil:
+		return nil, err
+	default:
+		rows.next = first
+	}
+
+	return rows, nil
+}
+
+func (c *bigqueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	job, err := c.query(query, args).Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := status.Err(); err != nil {
+		return nil, err
+	}
+
+	var affected int64
+	if stats, ok := status.Statistics.Details.(*bigquery.QueryStatistics); ok {
+		affected = stats.NumDMLAffectedRows
+	}
+
+	return driver.RowsAffected(affected), nil
+}
+
+func (c *bigqueryConn) Prepare(query string) (driver.Stmt, error) {
+	return &bigqueryStmt{conn: c, query: query}, nil
+}
+
+func (c *bigqueryConn) Close() error { return c.client.Close() }
+
+func (c *bigqueryConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("bigquery: transactions are not supported")
+}
+
+type bigqueryStmt struct {
+	conn  *bigqueryConn
+	query string
+}
+
+func (s *bigqueryStmt) Close() error  { return nil }
+func (s *bigqueryStmt) NumInput() int { return -1 }
+
+func (s *bigqueryStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *bigqueryStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+type bigqueryRows struct {
+	it   *bigquery.RowIterator
+	next []bigquery.Value
+	done bool
+}
+
+func (r *bigqueryRows) Columns() []string {
+	columns := make([]string, len(r.it.Schema))
+	for i, field := range r.it.Schema {
+		columns[i] = field.Name
+	}
+
+	return columns
+}
+
+func (r *bigqueryRows) Close() error { return nil }
+
+func (r *bigqueryRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	values := r.next
+	r.next = nil
+	if values == nil {
+		if err := r.it.Next(&values); err != nil {
+			if errors.Is(err, iterator.Done) {
+				r.done = true
+				return io.EOF
+			}
+			return err
+		}
+	}
+
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil, int64, float64, bool, string, []byte, time.Time:
+			dest[i] = v
+		default:
+			dest[i] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return nil
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return named
+}
+
+type bigqueryDialect struct{}
+
+func (bigqueryDialect) Name() string   { return "bigquery" }
+func (bigqueryDialect) Engine() string { return "bigquery" }
+
+func (bigqueryDialect) Detect(conn string) bool { return strings.HasPrefix(conn, "bigquery://") }
+
+func (bigqueryDialect) Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error parsing connection string: %w", err)
+	}
+
+	connector := &bigqueryConnector{
+		project: u.Host,
+		dataset: strings.TrimPrefix(u.Path, "/"),
+	}
+	if m.BigqueryCredentials != nil {
+		credentials, err := m.BigqueryCredentials.Plaintext(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting plaintext bigquery credentials: %w", err)
+		}
+		connector.credentials = []byte(credentials)
+	}
+
+	return sql.OpenDB(connector), connector.dataset, nil
+}
+
+func (bigqueryDialect) Quote(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "\\`") + "`"
+}
+
+// QuoteLiteral escapes quotes and backslashes with a backslash, BigQuery does not read a doubled quote as an escape
+func (bigqueryDialect) QuoteLiteral(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// VersionQuery is empty as BigQuery is a managed service without a server version
+func (bigqueryDialect) VersionQuery() string { return "" }
+
+func (d bigqueryDialect) TablesQuery(database, schema, _ string) string {
+	// schemas are datasets in BigQuery, so default to the dataset from the connection string
+	if schema == "public" {
+		schema = database
+	}
+
+	return fmt.Sprintf("SELECT table_name FROM %s.INFORMATION_SCHEMA.TABLES WHERE table_type = 'BASE TABLE'", d.Quote(schema))
+}
+
+func (d bigqueryDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM %s.INFORMATION_SCHEMA.COLUMNS WHERE table_name = %s ORDER BY ordinal_position", d.Quote(database), d.QuoteLiteral(table))
+}
+
+func (d bigqueryDialect) ColumnDetailsQuery(database, table string) string {
+	// column_default is the text NULL for columns without a default, the length and precision of a type are part of
+	// its name and descriptions are on the field paths
+	return fmt.Sprintf(`SELECT c.column_name, c.data_type, c.is_nullable, NULLIF(c.column_default, 'NULL'), NULL, NULL, NULL, 'NO', p.description, c.ordinal_position
+FROM %[1]s.INFORMATION_SCHEMA.COLUMNS c
+LEFT JOIN %[1]s.INFORMATION_SCHEMA.COLUMN_FIELD_PATHS p ON p.table_name = c.table_name AND p.field_path = c.column_name
+WHERE c.table_name = %[2]s ORDER BY c.ordinal_position`, d.Quote(database), d.QuoteLiteral(table))
+}
+
+func (d bigqueryDialect) SchemaQuery(database, schema string) string {
+	if schema == "public" {
+		schema = database
+	}
+
+	return fmt.Sprintf("SELECT table_name, column_name, data_type, is_nullable, column_default, ordinal_position FROM %s.INFORMATION_SCHEMA.COLUMNS ORDER BY table_name, ordinal_position", d.Quote(schema))
+}
+
+func (d bigqueryDialect) ViewsQuery(database, schema string) (string, error) {
+	if schema == "public" {
+		schema = database
+	}
+
+	return fmt.Sprintf("SELECT table_name, view_definition FROM %s.INFORMATION_SCHEMA.VIEWS ORDER BY table_name", d.Quote(schema)), nil
+}
+
+func (bigqueryDialect) SchemasQuery(_ string) (string, error) {
+	// schemas are the datasets of the project, without a region qualifier BigQuery lists the ones in the US
+	return "SELECT schema_name, NULL FROM INFORMATION_SCHEMA.SCHEMATA ORDER BY schema_name", nil
+}
+
+func (d bigqueryDialect) TableDDLQuery(database, table string) (string, error) {
+	// a table is in the dataset of the connection unless it is qualified with its own
+	dataset := database
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		dataset, table = table[:i], table[i+1:]
+	}
+
+	return fmt.Sprintf("SELECT ddl FROM %s.INFORMATION_SCHEMA.TABLES WHERE table_name = %s", d.Quote(dataset), d.QuoteLiteral(table)), nil
+}
+
+func (d bigqueryDialect) RowEstimatesQuery(database, schema string) (string, error) {
+	if schema == "public" {
+		schema = database
+	}
+
+	// __TABLES__ has the row counts of the tables of a dataset from its storage metadata, type 1 is a table
+	return fmt.Sprintf("SELECT table_id, row_count FROM %s.__TABLES__ WHERE type = 1 ORDER BY table_id", d.Quote(schema)), nil
+}
+
+func (d bigqueryDialect) TableSizesQuery(database, schema string) (string, error) {
+	if schema == "public" {
+		schema = database
+	}
+
+	return fmt.Sprintf("SELECT table_id, size_bytes, 0, 0, size_bytes FROM %s.__TABLES__ WHERE type = 1 ORDER BY size_bytes DESC, table_id", d.Quote(schema)), nil
+}
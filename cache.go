@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cacheImage = "alpine:3.21"
+	// cachePath is where the cache volume is mounted in the container that reads and writes entries
+	cachePath = "/cache"
+	// cacheHit prefixes the output of a read that found a fresh entry
+	cacheHit = "HIT\n"
+)
+
+// Cache the results of read-only queries in a cache volume, keyed by the query and the connection, so repeated runs against slowly changing data skip the database
+func (m *Sql) WithCache(
+	// Seconds a cached result stays fresh
+	// +default=300
+	ttlSeconds int,
+	// Name of the cache volume, use different names to keep caches apart
+	// +default="sql-query-cache"
+	volume string,
+) *Sql {
+	m.CacheTTL = ttlSeconds
+	m.CacheVolume = volume
+	return m
+}
+
+// cachedQuery returns a fresh cached result of a read-only query, or runs it and caches the result.
+// Queries that are not read-only always run.
+func (m *Sql) cachedQuery(ctx context.Context, query, options string, run func() (string, error)) (string, error) {
+	engine := "duckdb"
+	if !m.Duckdb {
+		_, d, _, err := m.connect(ctx)
+		if err != nil {
+			return "", fmt.Errorf("error opening database connection: %w", err)
+		}
+		engine = d.Engine()
+	}
+	if checkReadOnly(query, engine) != nil {
+		return run()
+	}
+
+	fingerprint, err := m.fingerprint(ctx)
+	if err != nil {
+		return "", err
+	}
+	// normalize whitespace so formatting changes do not miss the cache
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(fingerprint+"\x00"+strings.Join(strings.Fields(query), " ")+"\x00"+options)))
+
+	now := time.Now().Unix()
+	ctr := dag.Container().From(cacheImage).
+		WithMountedCache(cachePath, dag.CacheVolume(m.CacheVolume)).
+		WithEnvVariable("KEY", key).
+		// the current time changes the exec, so the layer cache never returns a stale read
+		WithEnvVariable("NOW", strconv.FormatInt(now, 10))
+
+	out, err := ctr.WithExec([]string{"sh", "-c", `f="` + cachePath + `/$KEY"; if [ -f "$f" ] && [ "$(head -n 1 "$f")" -gt "$NOW" ]; then printf 'HIT\n'; tail -n +2 "$f"; fi`}).Stdout(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error reading query cache: %w", err)
+	}
+	if result, ok := strings.CutPrefix(out, cacheHit); ok {
+		return result, nil
+	}
+
+	result, err := run()
+	if err != nil {
+		return "", err
+	}
+
+	entry := strconv.FormatInt(now+int64(m.CacheTTL), 10) + "\n" + result
+	_, err = ctr.WithNewFile("/tmp/entry", entry).
+		WithExec([]string{"sh", "-c", `cp /tmp/entry "` + cachePath + `/$KEY"`}).
+		Sync(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error writing query cache: %w", err)
+	}
+
+	return result, nil
+}
+
+// fingerprint identifies the data a query runs against without revealing the connection string
+func (m *Sql) fingerprint(ctx context.Context) (string, error) {
+	var parts []string
+	switch {
+	case m.Sqlite != nil:
+		digest, err := m.Sqlite.Digest(ctx)
+		if err != nil {
+			return "", fmt.Errorf("error getting SQLite file digest: %w", err)
+		}
+		parts = append(parts, "sqlite", digest)
+	case m.Duckdb:
+		parts = append(parts, "duckdb")
+		if m.DuckdbFile != nil {
+			digest, err := m.DuckdbFile.Digest(ctx)
+			if err != nil {
+				return "", fmt.Errorf("error getting DuckDB file digest: %w", err)
+			}
+			parts = append(parts, digest)
+		}
+		if m.DuckdbData != nil {
+			digest, err := m.DuckdbData.Digest(ctx)
+			if err != nil {
+				return "", fmt.Errorf("error getting DuckDB data digest: %w", err)
+			}
+			parts = append(parts, digest)
+		}
+	default:
+		dsn, err := m.dsn(ctx)
+		if err != nil {
+			return "", err
+		}
+		// session options change what a query sees
+		parts = append(parts, dsn, m.SessionSearchPath, m.SessionTimezone, m.SessionRole)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(parts, "\x00")))), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// killTimeout bounds the statement that stops a query on the server once its context has ended
+const killTimeout = 5 * time.Second
+
+// serverConn reserves a connection whose running statement is stopped on the server when ctx ends, e.g. when the
+// pipeline is cancelled. pgx sends a cancel request and sqlserver an attention packet, but the mysql driver only
+// closes the socket and leaves the query running.
+func serverConn(ctx context.Context, db *sql.DB, d dialect) (*sql.Conn, func(), error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if d.Engine() != "mysql" {
+		return conn, func() { conn.Close() }, nil
+	}
+
+	var id int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("error querying connection id: %w", err)
+	}
+
+	done := make(chan struct{})
+	killed := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), killTimeout)
+			defer cancel()
+			killed <- killQuery(ctx, db, id) == nil
+		case <-done:
+			killed <- false
+		}
+	}()
+
+	return conn, func() {
+		close(done)
+		if <-killed {
+			// a kill that arrives after the query finished would hit the next one, so discard the connection
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+// killConnector opens the connections of a database and gives the kill of a query its own connection through the
+// driver of the database, as the pool may have no free connection while the query holds one, e.g. with a pool of one
+type killConnector struct {
+	driver.Connector
+}
+
+func (c killConnector) Driver() driver.Driver { return connectorDriver{c.Connector} }
+
+// connectorDriver opens connections outside of the pool of a database with its connector
+type connectorDriver struct {
+	connector driver.Connector
+}
+
+func (d connectorDriver) Open(string) (driver.Conn, error) {
+	return d.connector.Connect(context.Background())
+}
+
+// killQuery stops the running query of a mysql connection from a new connection that is not part of the pool
+func killQuery(ctx context.Context, db *sql.DB, id int64) error {
+	d, ok := db.Driver().(connectorDriver)
+	if !ok {
+		return fmt.Errorf("the driver cannot open a connection to kill the query")
+	}
+
+	conn, err := d.connector.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("error opening connection to kill the query: %w", err)
+	}
+	defer conn.Close()
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		return fmt.Errorf("driver does not support executing statements")
+	}
+	_, err = execer.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id), nil)
+	return err
+}
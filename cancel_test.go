@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// killDriver is a mysql-like driver whose connections report id 42 and send the statements they execute
+type killDriver struct {
+	statements chan string
+}
+
+func (d *killDriver) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *killDriver) Driver() driver.Driver                        { return nil }
+func (d *killDriver) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (d *killDriver) Close() error                                 { return nil }
+func (d *killDriver) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (d *killDriver) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{columns: []string{"CONNECTION_ID()"}, rows: [][]driver.Value{{int64(42)}}}, nil
+}
+
+func (d *killDriver) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	d.statements <- query
+	return driver.RowsAffected(0), nil
+}
+
+func TestServerConnKillsOutsideOfThePool(t *testing.T) {
+	fake := &killDriver{statements: make(chan string, 1)}
+	db := sql.OpenDB(killConnector{fake})
+	defer db.Close()
+	// the query holds the only connection of the pool
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, release, err := serverConn(ctx, db, mysqlDialect{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	cancel()
+
+	select {
+	case statement := <-fake.statements:
+		if statement != "KILL QUERY 42" {
+			t.Errorf("executed %q, want KILL QUERY 42", statement)
+		}
+	case <-time.After(killTimeout):
+		t.Fatal("the query was not killed")
+	}
+}
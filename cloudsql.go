@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"net"
+
+	"cloud.google.com/go/cloudsqlconn"
+)
+
+// Connect to a Cloud SQL instance through the Cloud SQL connector instead of the host in the connection string (postgres and mysql only)
+func (m *Sql) WithCloudSQL(
+	// Instance connection name, e.g. project:region:instance
+	instance string,
+	// Service account key in JSON format
+	serviceAccountKey *dagger.Secret,
+) *Sql {
+	m.CloudSQLInstance = instance
+	m.CloudSQLKey = serviceAccountKey
+	return m
+}
+
+// cloudSQLDialer returns a dialer that connects to the Cloud SQL instance
+func (m *Sql) cloudSQLDialer(ctx context.Context) (dialFunc, error) {
+	key, err := m.CloudSQLKey.Plaintext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plaintext service account key: %w", err)
+	}
+
+	// the dialer stays open for the lifetime of the module call, so it must outlive a connect timeout
+	dialer, err := cloudsqlconn.NewDialer(context.WithoutCancel(ctx), cloudsqlconn.WithCredentialsJSON([]byte(key)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating Cloud SQL dialer: %w", err)
+	}
+
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.Dial(ctx, m.CloudSQLInstance)
+	}, nil
+}
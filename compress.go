@@ -0,0 +1,69 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"os"
+)
+
+// resultFile is a file in the module workdir that results are written to, gzipped when compressed
+type resultFile struct {
+	path string
+	f    *os.File
+	gz   *gzip.Writer
+}
+
+// createResultFile creates a results file, a compressed file has .gz added to its path
+func createResultFile(path string, compress bool) (*resultFile, error) {
+	if compress {
+		path += ".gz"
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating results file: %w", err)
+	}
+
+	r := &resultFile{path: path, f: f}
+	if compress {
+		r.gz = gzip.NewWriter(f)
+	}
+
+	return r, nil
+}
+
+func (r *resultFile) Write(p []byte) (int, error) {
+	if r.gz != nil {
+		return r.gz.Write(p)
+	}
+
+	return r.f.Write(p)
+}
+
+// Close flushes the compressed stream and closes the file
+func (r *resultFile) Close() error {
+	if r.gz != nil {
+		if err := r.gz.Close(); err != nil {
+			r.f.Close()
+			return err
+		}
+	}
+
+	return r.f.Close()
+}
+
+// file closes the results file and returns it
+func (r *resultFile) file(ctx context.Context) (*dagger.File, error) {
+	if err := r.Close(); err != nil {
+		return nil, fmt.Errorf("error writing results file: %w", err)
+	}
+
+	file, err := dag.CurrentModule().WorkdirFile(r.path).Sync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading results file: %w", err)
+	}
+
+	return file, nil
+}
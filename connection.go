@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// socketProxyImage is the image used to proxy a unix socket over TCP
+const socketProxyImage = "alpine/socat:1.8.0.0"
+
+// socketPorts maps an engine to the TCP port the unix socket proxy listens on, its default port
+var socketPorts = map[string]int{
+	"mysql":     3306,
+	"oracle":    1521,
+	"postgres":  5432,
+	"sqlserver": 1433,
+}
+
+// dsnBuilder is implemented by dialects that can assemble a connection string from its parts
+type dsnBuilder interface {
+	// BuildDSN returns a connection string, a port of 0 uses the default port of the driver
+	BuildDSN(host string, port int, user, password, database string) string
+}
+
+// Set the database driver used to build the connection string, e.g. postgres or mysql
+func (m *Sql) WithDriver(driver string) *Sql {
+	m.Driver = driver
+	return m
+}
+
+// Set the host of the database server, a path is used as the unix socket directory (postgres) or file (mysql)
+func (m *Sql) WithHost(host string) *Sql {
+	m.Host = host
+	return m
+}
+
+// Set the port of the database server
+func (m *Sql) WithPort(port int) *Sql {
+	m.Port = port
+	return m
+}
+
+// Set the user to connect as
+func (m *Sql) WithUser(user string) *Sql {
+	m.User = user
+	return m
+}
+
+// Set the password of the user
+func (m *Sql) WithPassword(password *dagger.Secret) *Sql {
+	m.Password = password
+	return m
+}
+
+// Connect to a database service started elsewhere in the pipeline, the host and port are derived from the service
+func (m *Sql) WithService(
+	svc *dagger.Service,
+	// Port of the service to connect to, defaults to the first exposed port
+	// +optional
+	port int,
+) *Sql {
+	m.Service = svc
+	m.Port = port
+	return m
+}
+
+// Connect through a unix socket from the host, e.g. /var/run/postgresql/.s.PGSQL.5432, by proxying it over TCP (requires with-driver)
+func (m *Sql) WithUnixSocket(socket *dagger.Socket) *Sql {
+	m.Socket = socket
+	return m
+}
+
+// socketProxy returns a service that proxies the unix socket on a TCP port, as the module cannot dial a socket directly
+func (m *Sql) socketProxy(port int) *dagger.Service {
+	return dag.Container().
+		From(socketProxyImage).
+		WithUnixSocket("/tmp/database.sock", m.Socket).
+		WithExposedPort(port).
+		AsService(dagger.ContainerAsServiceOpts{Args: []string{
+			"socat", fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", port), "UNIX-CONNECT:/tmp/database.sock",
+		}})
+}
+
+// Set the name of the database to connect to
+func (m *Sql) WithDatabase(database string) *Sql {
+	m.Database = database
+	return m
+}
+
+// dsn returns the connection string, either the secret or one built from the connection options
+func (m *Sql) dsn(ctx context.Context) (string, error) {
+	if m.Conn != nil {
+		// the connection string names its own endpoint, so the options would be ignored
+		if m.Service != nil || m.Socket != nil || m.Host != "" || m.Port != 0 {
+			return "", fmt.Errorf("a connection string cannot be combined with with-service, with-unix-socket, with-host or with-port")
+		}
+
+		c, err := m.Conn.Plaintext(ctx)
+		if err != nil {
+			return "", fmt.Errorf("error getting plaintext connection: %w", err)
+		}
+
+		// dynamic credentials from vault replace the ones in the connection string
+		if m.VaultLease != "" {
+			return m.withCredentials(ctx, c)
+		}
+
+		return c, nil
+	}
+
+	if m.Driver == "" {
+		return "", fmt.Errorf("no connection string or driver provided")
+	}
+
+	d, err := dialectByName(m.Driver)
+	if err != nil {
+		return "", err
+	}
+
+	b, ok := d.(dsnBuilder)
+	if !ok {
+		return "", fmt.Errorf("the %s driver requires a connection string", m.Driver)
+	}
+
+	var password string
+	if m.Password != nil {
+		if password, err = m.Password.Plaintext(ctx); err != nil {
+			return "", fmt.Errorf("error getting plaintext password: %w", err)
+		}
+	}
+
+	host, port := m.Host, m.Port
+	switch {
+	case m.Socket != nil:
+		if m.Service != nil || m.Host != "" || m.Port != 0 {
+			return "", fmt.Errorf("a unix socket cannot be combined with with-service, with-host or with-port")
+		}
+		socketPort, ok := socketPorts[d.Engine()]
+		if !ok {
+			return "", fmt.Errorf("unix sockets are not supported for %s", d.Name())
+		}
+		if host, port, err = serviceEndpoint(ctx, m.socketProxy(socketPort), socketPort); err != nil {
+			return "", err
+		}
+	case m.Service != nil:
+		if host, port, err = serviceEndpoint(ctx, m.Service, m.Port); err != nil {
+			return "", err
+		}
+	}
+
+	return b.BuildDSN(host, port, m.User, password, m.Database), nil
+}
+
+// withCredentials replaces the user and password of a connection string with the configured ones
+func (m *Sql) withCredentials(ctx context.Context, dsn string) (string, error) {
+	password, err := m.Password.Plaintext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting plaintext password: %w", err)
+	}
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("error parsing connection string: %w", err)
+		}
+		u.User = userInfo(m.User, password)
+
+		return u.String(), nil
+	}
+
+	// the mysql driver uses its own DSN format
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("unable to set credentials on connection string: %w", err)
+	}
+	cfg.User = m.User
+	cfg.Passwd = password
+
+	return cfg.FormatDSN(), nil
+}
+
+// serviceEndpoint starts a database service and returns the host and port it is reachable on, a port of 0 is the first
+// exposed port
+func serviceEndpoint(ctx context.Context, svc *dagger.Service, exposedPort int) (string, int, error) {
+	svc, err := svc.Start(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("error starting database service: %w", err)
+	}
+
+	endpoint, err := svc.Endpoint(ctx, dagger.ServiceEndpointOpts{Port: exposedPort})
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting database service endpoint: %w", err)
+	}
+
+	host, p, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, fmt.Errorf("error parsing database service endpoint: %w", err)
+	}
+
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return "", 0, fmt.Errorf("error parsing database service port: %w", err)
+	}
+
+	return host, port, nil
+}
+
+// dialer returns the dialer to reach the database through, or nil to let the driver dial the host directly
+func (m *Sql) dialer(ctx context.Context) (dialFunc, error) {
+	if m.CloudSQLInstance != "" {
+		return m.cloudSQLDialer(ctx)
+	}
+
+	return m.sshDialer(ctx)
+}
+
+// tokenAuth reports whether the password is replaced by a short-lived token for every new connection
+func (m *Sql) tokenAuth() bool {
+	return m.AWSCredentials != nil || m.AzureTenant != ""
+}
+
+// authToken generates a short-lived token to use as the password of the user on the endpoint (host:port)
+func (m *Sql) authToken(ctx context.Context, endpoint, user string) (string, error) {
+	if m.AzureTenant != "" {
+		return m.azureToken(ctx)
+	}
+
+	return m.rdsAuthToken(ctx, endpoint, user)
+}
+
+// hostPort joins a host and port, leaving out the port when it is 0
+func hostPort(host string, port int) string {
+	if port == 0 {
+		return host
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// userInfo returns the escaped user and password for a URL
+func userInfo(user, password string) *url.Userinfo {
+	if password == "" {
+		return url.User(user)
+	}
+
+	return url.UserPassword(user, password)
+}
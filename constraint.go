@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Constraint represents a check, unique or exclusion constraint of a table
+type Constraint struct {
+	Name string
+	// Type is CHECK, UNIQUE or EXCLUDE
+	Type string
+	// Columns are the columns the constraint covers, in order for unique constraints
+	Columns []string
+	// Definition is the SQL that defines the constraint, e.g. CHECK (price > 0)
+	Definition string
+}
+
+// constraintLister is implemented by dialects that can list the constraints of a table
+type constraintLister interface {
+	// ConstraintsQuery lists the constraint name, type, column and definition of every column of the check, unique and
+	// exclusion constraints of a table, ordered by constraint and position, the definition of unique constraints can be
+	// NULL and is then built from their columns
+	ConstraintsQuery(database, table string) (string, error)
+}
+
+// List the check, unique and exclusion constraints of a table with their definitions
+func (m *Sql) ListConstraints(ctx context.Context, table string) ([]*Constraint, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(constraintLister)
+	if !ok {
+		return nil, fmt.Errorf("listing constraints is not supported for %s", d.Name())
+	}
+	query, err := l.ConstraintsQuery(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying constraints: %w", err)
+	}
+	defer rows.Close()
+
+	constraints := []*Constraint{}
+	var constraint *Constraint
+	for rows.Next() {
+		var name, kind string
+		var column, definition sql.NullString
+		if err := rows.Scan(&name, &kind, &column, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by constraint, so a new name starts the next constraint
+		if constraint == nil || constraint.Name != name {
+			constraint = &Constraint{Name: name, Type: kind, Columns: []string{}, Definition: definition.String}
+			constraints = append(constraints, constraint)
+		}
+		// check constraints on the whole table have no columns
+		if column.Valid {
+			constraint.Columns = append(constraint.Columns, column.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, constraint := range constraints {
+		if constraint.Definition == "" && constraint.Type == "UNIQUE" {
+			constraint.Definition = fmt.Sprintf("UNIQUE (%s)", strings.Join(constraint.Columns, ", "))
+		}
+	}
+
+	return constraints, nil
+}
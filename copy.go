@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// copyPath is where a file loaded with COPY is written inside the module workdir
+const copyPath = "copy.in"
+
+// Bulk load a file into a table with the postgres COPY protocol, which is far faster than inserting rows one by one
+func (m *Sql) CopyIn(
+	ctx context.Context,
+	// Table to load, optionally qualified with its schema, e.g. public.users
+	table string,
+	file *dagger.File,
+	// Format of the file, csv (with a header row), text or binary
+	// +default="csv"
+	format string,
+	// Columns the fields of the file map to, in order, all columns of the table by default
+	// +optional
+	columns []string,
+) (*ExecResult, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := copySupported(d); err != nil {
+		return nil, err
+	}
+
+	options, err := copyOptions(format)
+	if err != nil {
+		return nil, err
+	}
+
+	target := quoteQualified(d, table)
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = d.Quote(column)
+		}
+		target += " (" + strings.Join(quoted, ", ") + ")"
+	}
+	statement := fmt.Sprintf("COPY %s FROM STDIN WITH (%s)", target, options)
+	if err := m.checkPolicy(d.Engine(), statement); err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Export(ctx, copyPath); err != nil {
+		return nil, fmt.Errorf("error exporting file: %w", err)
+	}
+	f, err := os.Open(copyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	conn, release, err := serverConn(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var copied int64
+	err = conn.Raw(func(driverConn any) error {
+		tag, err := driverConn.(*stdlib.Conn).Conn().PgConn().CopyFrom(ctx, f, statement)
+		copied = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error copying into %s: %w", table, err)
+	}
+
+	return &ExecResult{RowsAffected: int(copied)}, nil
+}
+
+// Export the results of a query with the postgres COPY protocol, which streams them straight into a file
+func (m *Sql) CopyOut(
+	ctx context.Context,
+	query string,
+	// Format of the file, csv (with a header row), text or binary
+	// +default="csv"
+	format string,
+	// Gzip the file, adding .gz to its name
+	// +optional
+	compress bool,
+) (*dagger.File, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := copySupported(d); err != nil {
+		return nil, err
+	}
+	if err := m.checkPolicy(d.Engine(), query); err != nil {
+		return nil, err
+	}
+
+	options, err := copyOptions(format)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := createResultFile(resultPath, compress)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	copyCtx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	conn, release, err := serverConn(copyCtx, db, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	statement := fmt.Sprintf("COPY (%s) TO STDOUT WITH (%s)", strings.TrimRight(strings.TrimSpace(query), ";"), options)
+	err = conn.Raw(func(driverConn any) error {
+		_, err := driverConn.(*stdlib.Conn).Conn().PgConn().CopyTo(copyCtx, f, statement)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error copying query results: %w", err)
+	}
+
+	return f.file(ctx)
+}
+
+// copySupported returns an error for databases without the COPY protocol, redshift only copies from cloud storage
+func copySupported(d dialect) error {
+	if d.Engine() != "postgres" || d.Name() == "redshift" {
+		return fmt.Errorf("copy is not supported for %s", d.Name())
+	}
+
+	return nil
+}
+
+// copyOptions returns the COPY options of a file format
+func copyOptions(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return "FORMAT csv, HEADER true", nil
+	case "text":
+		return "FORMAT text", nil
+	case "binary":
+		return "FORMAT binary", nil
+	}
+
+	return "", fmt.Errorf("unsupported copy format %q, supported formats are csv, text and binary", format)
+}
+
+// quoteQualified quotes every part of a dot-separated name, e.g. a schema-qualified table
+func quoteQualified(d dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = d.Quote(part)
+	}
+
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// cursorName is the name of the server-side cursor a query is fetched through
+const cursorName = "dagger_sql_cursor"
+
+// streamCursor declares a server-side cursor for a query and fetches its rows in batches, which keeps the memory of the
+// server and the module flat for result sets of any size
+func streamCursor(ctx context.Context, db *sql.DB, d dialect, query string, batchSize int, w rowWriter, args ...any) error {
+	// the query is interpolated into DECLARE, so a second statement would run outside of the cursor
+	if statements := splitStatements(query, d.Engine()); len(statements) != 1 {
+		return fmt.Errorf("cursors need exactly one statement, found %d", len(statements))
+	}
+
+	conn, release, err := serverConn(ctx, db, d)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// cursors only live within a transaction
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", cursorName, strings.TrimRight(strings.TrimSpace(query), ";")), args...); err != nil {
+		return fmt.Errorf("error declaring cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", batchSize, cursorName)
+	for batch := 0; ; batch++ {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("error fetching rows: %w", err)
+		}
+
+		columns, err := rows.ColumnTypes()
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("error getting columns: %w", err)
+		}
+		if batch == 0 {
+			if err := w.header(columns); err != nil {
+				rows.Close()
+				return fmt.Errorf("error writing header: %w", err)
+			}
+		}
+
+		fetched := 0
+		for rows.Next() {
+			values, err := scanRow(rows, len(columns))
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			if err := w.row(values); err != nil {
+				rows.Close()
+				return fmt.Errorf("error writing row: %w", err)
+			}
+			fetched++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		if fetched < batchSize {
+			break
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "CLOSE "+cursorName); err != nil {
+		return fmt.Errorf("error closing cursor: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	if err := w.flush(); err != nil {
+		return fmt.Errorf("error writing results: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// EnumType represents a postgres enum and its labels in sort order
+type EnumType struct {
+	Schema string
+	Name   string
+	Labels []string
+}
+
+// DomainType represents a postgres domain, a base type with constraints
+type DomainType struct {
+	Schema   string
+	Name     string
+	BaseType string
+	NotNull  bool
+	// Default is the default expression of the domain, empty when it has none
+	Default string
+	// Checks are the definitions of the check constraints of the domain, e.g. CHECK (VALUE > 0)
+	Checks []string
+}
+
+// CompositeType represents a postgres composite type created with CREATE TYPE ... AS
+type CompositeType struct {
+	Schema     string
+	Name       string
+	Attributes []*CompositeAttribute
+}
+
+// CompositeAttribute represents an attribute of a composite type
+type CompositeAttribute struct {
+	Name     string
+	DataType string
+}
+
+// List the enum types outside the system schemas with their labels in sort order (postgres only)
+func (m *Sql) ListEnums(ctx context.Context) ([]*EnumType, error) {
+	rows, cancel, err := m.queryCustomTypes(ctx, `SELECT n.nspname, t.typname, e.enumlabel
+FROM pg_type t
+JOIN pg_namespace n ON n.oid = t.typnamespace
+JOIN pg_enum e ON e.enumtypid = t.oid
+WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY n.nspname, t.typname, e.enumsortorder`)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer rows.Close()
+
+	enums := []*EnumType{}
+	var enum *EnumType
+	for rows.Next() {
+		var schema, name, label string
+		if err := rows.Scan(&schema, &name, &label); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by type, so a new schema and name starts the next type
+		if enum == nil || enum.Schema != schema || enum.Name != name {
+			enum = &EnumType{Schema: schema, Name: name}
+			enums = append(enums, enum)
+		}
+		enum.Labels = append(enum.Labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return enums, nil
+}
+
+// List the domains outside the system schemas with their base types and constraints (postgres only)
+func (m *Sql) ListDomains(ctx context.Context) ([]*DomainType, error) {
+	rows, cancel, err := m.queryCustomTypes(ctx, `SELECT n.nspname, t.typname, format_type(t.typbasetype, t.typtypmod), t.typnotnull, t.typdefault, pg_get_constraintdef(c.oid, true)
+FROM pg_type t
+JOIN pg_namespace n ON n.oid = t.typnamespace
+LEFT JOIN pg_constraint c ON c.contypid = t.oid AND c.contype = 'c'
+WHERE t.typtype = 'd' AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY n.nspname, t.typname, c.conname`)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer rows.Close()
+
+	domains := []*DomainType{}
+	var domain *DomainType
+	for rows.Next() {
+		var schema, name, baseType string
+		var notNull bool
+		var columnDefault, check sql.NullString
+		if err := rows.Scan(&schema, &name, &baseType, &notNull, &columnDefault, &check); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by type, so a new schema and name starts the next type
+		if domain == nil || domain.Schema != schema || domain.Name != name {
+			domain = &DomainType{Schema: schema, Name: name, BaseType: baseType, NotNull: notNull, Default: columnDefault.String, Checks: []string{}}
+			domains = append(domains, domain)
+		}
+		if check.Valid {
+			domain.Checks = append(domain.Checks, check.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return domains, nil
+}
+
+// List the composite types outside the system schemas with their attributes in order (postgres only)
+func (m *Sql) ListCompositeTypes(ctx context.Context) ([]*CompositeType, error) {
+	// every table has a composite row type too, only the stand-alone ones have a relation of kind c
+	rows, cancel, err := m.queryCustomTypes(ctx, `SELECT n.nspname, t.typname, a.attname, format_type(a.atttypid, a.atttypmod)
+FROM pg_type t
+JOIN pg_namespace n ON n.oid = t.typnamespace
+JOIN pg_class c ON c.oid = t.typrelid AND c.relkind = 'c'
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY n.nspname, t.typname, a.attnum`)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer rows.Close()
+
+	types := []*CompositeType{}
+	var composite *CompositeType
+	for rows.Next() {
+		var schema, name string
+		attribute := &CompositeAttribute{}
+		if err := rows.Scan(&schema, &name, &attribute.Name, &attribute.DataType); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by type, so a new schema and name starts the next type
+		if composite == nil || composite.Schema != schema || composite.Name != name {
+			composite = &CompositeType{Schema: schema, Name: name}
+			types = append(types, composite)
+		}
+		composite.Attributes = append(composite.Attributes, attribute)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return types, nil
+}
+
+// queryCustomTypes runs a query on the postgres type catalog, the cancel func must be called once the rows are read
+func (m *Sql) queryCustomTypes(ctx context.Context, query string) (*sql.Rows, context.CancelFunc, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	// redshift has no user defined types
+	if d.Engine() != "postgres" || d.Name() == "redshift" {
+		return nil, nil, fmt.Errorf("custom types are not supported for %s", d.Name())
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("error querying types: %w", err)
+	}
+
+	return rows, cancel, nil
+}
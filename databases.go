@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DatabaseDetails represents a database on the server
+type DatabaseDetails struct {
+	Name string
+	// Owner is the user or role that owns the database, empty for databases without owners
+	Owner string
+}
+
+// databaseLister is implemented by dialects that can list the databases on their server
+type databaseLister interface {
+	// DatabasesQuery lists the name and owner of the databases on the server, ordered by name
+	DatabasesQuery() (string, error)
+}
+
+// List the databases (catalogs for trino) on the server with their owners, leaving out templates and system databases
+func (m *Sql) ListDatabases(ctx context.Context) ([]*DatabaseDetails, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(databaseLister)
+	if !ok {
+		return nil, fmt.Errorf("listing databases is not supported for %s", d.Name())
+	}
+	query, err := l.DatabasesQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying databases: %w", err)
+	}
+	defer rows.Close()
+
+	databases := []*DatabaseDetails{}
+	for rows.Next() {
+		database := &DatabaseDetails{}
+		var owner sql.NullString
+		if err := rows.Scan(&database.Name, &owner); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		database.Owner = owner.String
+		databases = append(databases, database)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return databases, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// tableDDLQuerier is implemented by dialects that can reconstruct the statements that create a table
+type tableDDLQuerier interface {
+	// TableDDLQuery lists the CREATE TABLE statement of a table followed by the statements of its indexes, a statement
+	// is the last column of its row as SHOW CREATE TABLE puts the table name first
+	TableDDLQuery(database, table string) (string, error)
+}
+
+// Reconstruct the CREATE TABLE statement of a table with its columns, defaults and constraints, followed by its indexes
+func (m *Sql) TableDDL(ctx context.Context, table string) (string, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	q, ok := d.(tableDDLQuerier)
+	if !ok {
+		return "", fmt.Errorf("table ddl is not supported for %s", d.Name())
+	}
+	query, err := q.TableDDLQuery(database, table)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("error querying table ddl: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", fmt.Errorf("error reading columns: %w", err)
+	}
+
+	statements := []string{}
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return "", fmt.Errorf("error scanning row: %w", err)
+		}
+
+		statement := strings.TrimSpace(values[len(values)-1].String)
+		if statement == "" {
+			continue
+		}
+		if !strings.HasSuffix(statement, ";") {
+			statement += ";"
+		}
+		statements = append(statements, statement)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating rows: %w", err)
+	}
+	if len(statements) == 0 {
+		return "", fmt.Errorf("table %s does not exist", table)
+	}
+
+	return strings.Join(statements, "\n\n") + "\n", nil
+}
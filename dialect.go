@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// dialect describes how to connect to and introspect a database engine
+type dialect interface {
+	// Name is the database type, e.g. postgres or mariadb
+	Name() string
+	// Engine is the protocol family of the database, e.g. mysql for mariadb
+	Engine() string
+	// Detect reports whether a lower-cased connection string belongs to the dialect
+	Detect(conn string) bool
+	// Open opens a connection and returns the name of the database it points to
+	Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error)
+	// Quote quotes an identifier such as a table, schema or catalog name
+	Quote(identifier string) string
+	// QuoteLiteral quotes a string literal such as a table or schema name in an introspection query
+	QuoteLiteral(value string) string
+	// VersionQuery returns the server version as a single value
+	VersionQuery() string
+	// TablesQuery lists the names of the tables in a schema
+	TablesQuery(database, schema, catalog string) string
+	// ColumnsQuery lists the names of the columns in a table
+	ColumnsQuery(database, table string) string
+	// ColumnDetailsQuery lists the name, data type, nullability (YES or NO), default, character maximum length,
+	// numeric precision and scale, identity (YES or NO), comment and position of the columns in a table, ordered by position
+	ColumnDetailsQuery(database, table string) string
+	// SchemaQuery lists the table name, column name, data type, nullability (YES or NO), default and position of every
+	// column in a schema, ordered by table and position
+	SchemaQuery(database, schema string) string
+}
+
+// flavorDetector is implemented by dialects whose wire protocol is shared with other engines
+type flavorDetector interface {
+	// DetectFlavor returns the dialect of the engine behind an open connection
+	DetectFlavor(ctx context.Context, db *sql.DB, conn string) (dialect, error)
+}
+
+// dialects is the registry of supported database engines, in the order connection strings are detected
+var dialects = []dialect{
+	redshiftDialect{},
+	yugabyteDialect{},
+	postgresDialect{},
+	mysqlDialect{},
+	mariadbDialect{},
+	tidbDialect{},
+	singlestoreDialect{},
+	sqlserverDialect{},
+	oracleDialect{},
+	snowflakeDialect{},
+	bigqueryDialect{},
+	trinoDialect{},
+	sqliteDialect{},
+}
+
+// detectDialect returns the registered dialect for a connection string
+func detectDialect(conn string) (dialect, error) {
+	lower := strings.ToLower(conn)
+	for _, d := range dialects {
+		if d.Detect(lower) {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to determine database type from connection string: %s", conn)
+}
+
+// dialectByName returns the registered dialect with the given name
+func dialectByName(name string) (dialect, error) {
+	for _, d := range dialects {
+		if d.Name() == strings.ToLower(name) {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported database type: %s", name)
+}
+
+// serverVersion returns the version string reported by the database server, empty for managed services without a
+// server version such as bigquery
+func serverVersion(ctx context.Context, db *sql.DB, d dialect) (string, error) {
+	query := d.VersionQuery()
+	if query == "" {
+		return "", nil
+	}
+
+	var version string
+	if err := db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return "", fmt.Errorf("error querying server version: %w", err)
+	}
+
+	return version, nil
+}
+
+// List the database types the module can connect to
+func (m *Sql) SupportedDialects() []string {
+	names := make([]string, 0, len(dialects)+1)
+	for _, d := range dialects {
+		names = append(names, d.Name())
+	}
+
+	// duckdb runs in a container instead of through database/sql, see WithDuckdb
+	return append(names, "duckdb")
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     dialect
+		value string
+		want  string
+	}{
+		{name: "postgres", d: postgresDialect{}, value: "users", want: "'users'"},
+		{name: "postgres quote", d: postgresDialect{}, value: "it's", want: "'it''s'"},
+		{name: "postgres backslash", d: postgresDialect{}, value: `users\`, want: `'users\'`},
+		{name: "redshift", d: redshiftDialect{}, value: "it's", want: "'it''s'"},
+		{name: "mysql quote", d: mysqlDialect{}, value: "it's", want: "'it''s'"},
+		{name: "mysql backslash", d: mysqlDialect{}, value: `users\`, want: `'users\\'`},
+		{name: "mysql backslash and quote", d: mysqlDialect{}, value: `\' OR 1=1 --`, want: `'\\'' OR 1=1 --'`},
+		{name: "mariadb backslash", d: mariadbDialect{}, value: `users\`, want: `'users\\'`},
+		{name: "snowflake backslash", d: snowflakeDialect{}, value: `users\`, want: `'users\\'`},
+		{name: "bigquery quote", d: bigqueryDialect{}, value: "it's", want: `'it\'s'`},
+		{name: "bigquery backslash", d: bigqueryDialect{}, value: `users\`, want: `'users\\'`},
+		{name: "sqlserver", d: sqlserverDialect{}, value: "it's", want: "'it''s'"},
+		{name: "oracle", d: oracleDialect{}, value: "it's", want: "'it''s'"},
+		{name: "sqlite", d: sqliteDialect{}, value: "it's", want: "'it''s'"},
+		{name: "trino", d: trinoDialect{}, value: "it's", want: "'it''s'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.QuoteLiteral(tt.value); got != tt.want {
+				t.Errorf("QuoteLiteral(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"strings"
+)
+
+// SchemaDiff represents the differences between the schemas of two databases
+type SchemaDiff struct {
+	// Changes turn this schema into the other one, e.g. an added column is only in the other schema
+	Changes []*SchemaChange
+	// Summary describes the changes one per line, + added, - removed and ~ changed
+	Summary string
+}
+
+// SchemaChange represents a table, column, key, index or constraint that differs between two schemas
+type SchemaChange struct {
+	// Kind is added, removed or changed
+	Kind string
+	// Object is table, column, primary key, index, constraint or foreign key
+	Object string
+	Table  string
+	// Name is the name of the column, index or constraint, empty for tables and primary keys
+	Name string
+	// Current is the definition in this schema, empty when the object was added
+	Current string
+	// Other is the definition in the other schema, empty when the object was removed
+	Other string
+}
+
+// Compare the tables, columns, keys, indexes and constraints of a schema with the schema of another database
+func (m *Sql) DiffSchemas(
+	ctx context.Context,
+	// Connection string of the database to compare with, it uses the same options as this one
+	other *dagger.Secret,
+	// +default="public"
+	schema string,
+	// Schema of the other database, the same schema by default
+	// +optional
+	otherSchema string,
+) (*SchemaDiff, error) {
+	if otherSchema == "" {
+		otherSchema = schema
+	}
+
+	current, err := m.snapshotSchema(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	target, err := m.withConnection(other).snapshotSchema(ctx, otherSchema)
+	if err != nil {
+		return nil, fmt.Errorf("error reading other schema: %w", err)
+	}
+
+	return diffSnapshots(current, target), nil
+}
+
+// withConnection returns a copy of the module that connects with another connection string and the same options
+func (m *Sql) withConnection(conn *dagger.Secret) *Sql {
+	o := *m
+	o.Conn = conn
+	o.Sqlite = nil
+	o.Duckdb = false
+	// dynamic credentials from vault belong to the database they were issued for
+	o.VaultLease = ""
+	o.db, o.dbDialect, o.dbDatabase = nil, nil, ""
+	return &o
+}
+
+// definition is a named object of a table and the text it is compared by
+type definition struct {
+	name string
+	text string
+}
+
+// diffSnapshots returns the changes that turn one schema snapshot into another
+func diffSnapshots(from, to *SchemaSnapshot) *SchemaDiff {
+	diff := &SchemaDiff{Changes: []*SchemaChange{}}
+
+	toTables := make(map[string]*TableSnapshot, len(to.Tables))
+	for _, table := range to.Tables {
+		toTables[table.Name] = table
+	}
+	fromTables := make(map[string]bool, len(from.Tables))
+	for _, table := range from.Tables {
+		fromTables[table.Name] = true
+		other, ok := toTables[table.Name]
+		if !ok {
+			diff.Changes = append(diff.Changes, &SchemaChange{Kind: "removed", Object: "table", Table: table.Name, Current: tableDefinition(table)})
+			continue
+		}
+		diff.Changes = append(diff.Changes, diffTable(table, other)...)
+	}
+	for _, table := range to.Tables {
+		if !fromTables[table.Name] {
+			diff.Changes = append(diff.Changes, &SchemaChange{Kind: "added", Object: "table", Table: table.Name, Other: tableDefinition(table)})
+		}
+	}
+
+	lines := make([]string, len(diff.Changes))
+	for i, change := range diff.Changes {
+		lines[i] = change.summary()
+	}
+	diff.Summary = strings.Join(lines, "\n")
+	if diff.Summary == "" {
+		diff.Summary = "no differences"
+	}
+
+	return diff
+}
+
+// diffTable returns the changes that turn one table into another with the same name
+func diffTable(from, to *TableSnapshot) []*SchemaChange {
+	changes := diffDefinitions("column", from.Name, columnDefinitions(from), columnDefinitions(to))
+	changes = append(changes, diffDefinitions("primary key", from.Name, primaryKeyDefinitions(from), primaryKeyDefinitions(to))...)
+	changes = append(changes, diffDefinitions("index", from.Name, indexDefinitions(from), indexDefinitions(to))...)
+	changes = append(changes, diffDefinitions("constraint", from.Name, constraintDefinitions(from), constraintDefinitions(to))...)
+	changes = append(changes, diffDefinitions("foreign key", from.Name, foreignKeyDefinitions(from), foreignKeyDefinitions(to))...)
+
+	return changes
+}
+
+// diffDefinitions compares the objects of a table by name, in the order of the first table and then the second
+func diffDefinitions(object, table string, from, to []definition) []*SchemaChange {
+	changes := []*SchemaChange{}
+
+	toText := make(map[string]string, len(to))
+	for _, d := range to {
+		toText[d.name] = d.text
+	}
+	fromText := make(map[string]bool, len(from))
+	for _, d := range from {
+		fromText[d.name] = true
+		other, ok := toText[d.name]
+		switch {
+		case !ok:
+			changes = append(changes, &SchemaChange{Kind: "removed", Object: object, Table: table, Name: d.name, Current: d.text})
+		case other != d.text:
+			changes = append(changes, &SchemaChange{Kind: "changed", Object: object, Table: table, Name: d.name, Current: d.text, Other: other})
+		}
+	}
+	for _, d := range to {
+		if !fromText[d.name] {
+			changes = append(changes, &SchemaChange{Kind: "added", Object: object, Table: table, Name: d.name, Other: d.text})
+		}
+	}
+
+	return changes
+}
+
+// tableDefinition describes a table by its columns
+func tableDefinition(table *TableSnapshot) string {
+	columns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = column.Name
+	}
+
+	return "(" + strings.Join(columns, ", ") + ")"
+}
+
+func columnDefinitions(table *TableSnapshot) []definition {
+	definitions := make([]definition, len(table.Columns))
+	for i, column := range table.Columns {
+		text := column.DataType
+		if !column.IsNullable {
+			text += " NOT NULL"
+		}
+		if column.Default != "" {
+			text += " DEFAULT " + column.Default
+		}
+		definitions[i] = definition{name: column.Name, text: text}
+	}
+
+	return definitions
+}
+
+func primaryKeyDefinitions(table *TableSnapshot) []definition {
+	if len(table.PrimaryKey) == 0 {
+		return nil
+	}
+
+	return []definition{{text: "(" + strings.Join(table.PrimaryKey, ", ") + ")"}}
+}
+
+func indexDefinitions(table *TableSnapshot) []definition {
+	definitions := make([]definition, len(table.Indexes))
+	for i, index := range table.Indexes {
+		text := fmt.Sprintf("%s (%s)", index.Method, strings.Join(index.Columns, ", "))
+		if index.Unique {
+			text = "unique " + text
+		}
+		definitions[i] = definition{name: index.Name, text: text}
+	}
+
+	return definitions
+}
+
+func constraintDefinitions(table *TableSnapshot) []definition {
+	definitions := make([]definition, len(table.Constraints))
+	for i, constraint := range table.Constraints {
+		definitions[i] = definition{name: constraint.Name, text: constraint.Definition}
+	}
+
+	return definitions
+}
+
+func foreignKeyDefinitions(table *TableSnapshot) []definition {
+	definitions := make([]definition, len(table.ForeignKeys))
+	for i, key := range table.ForeignKeys {
+		text := fmt.Sprintf("(%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s", strings.Join(key.Columns, ", "), key.ReferencedTable, strings.Join(key.ReferencedColumns, ", "), key.OnDelete, key.OnUpdate)
+		definitions[i] = definition{name: key.Name, text: text}
+	}
+
+	return definitions
+}
+
+// summary describes a change in a line, e.g. ~ column users.name: varchar(20) -> text
+func (c *SchemaChange) summary() string {
+	name := c.Table
+	if c.Name != "" {
+		name += "." + c.Name
+	}
+
+	switch c.Kind {
+	case "added":
+		return fmt.Sprintf("+ %s %s %s", c.Object, name, c.Other)
+	case "removed":
+		return fmt.Sprintf("- %s %s", c.Object, name)
+	}
+
+	return fmt.Sprintf("~ %s %s: %s -> %s", c.Object, name, c.Current, c.Other)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// explainable lists the leading keywords of statements that EXPLAIN accepts, other statements are only prepared
+var explainable = map[string]bool{
+	"select":  true,
+	"insert":  true,
+	"update":  true,
+	"delete":  true,
+	"with":    true,
+	"replace": true,
+	"merge":   true,
+}
+
+// dryRun shows the plan of explainable statements and prepares the others, so the server checks them without running anything
+func (m *Sql) dryRun(ctx context.Context, statements []string) (string, error) {
+	if m.Duckdb {
+		return "", fmt.Errorf("dry runs are not supported for duckdb")
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	var plans []string
+	for i, statement := range statements {
+		keyword, _, _ := strings.Cut(strings.TrimLeft(stripComments(statement), "("), " ")
+		if e, ok := d.(explainer); ok && explainable[strings.ToLower(strings.TrimSpace(keyword))] {
+			query, err := e.ExplainQuery(statement, false, "text")
+			if err != nil {
+				return "", err
+			}
+			result, err := m.fetch(ctx, query, queryOptions{internal: true})
+			if err != nil {
+				return "", fmt.Errorf("error explaining statement %d: %w", i+1, err)
+			}
+			plans = append(plans, fmt.Sprintf("-- statement %d\n%s", i+1, result.csv("")))
+			continue
+		}
+
+		stmtCtx, cancel := m.statementContext(ctx)
+		stmt, err := db.PrepareContext(stmtCtx, statement)
+		cancel()
+		if err != nil {
+			return "", fmt.Errorf("error preparing statement %d: %w", i+1, err)
+		}
+		stmt.Close()
+		plans = append(plans, fmt.Sprintf("-- statement %d\nprepared without running, %s cannot explain it", i+1, d.Name()))
+	}
+
+	return strings.Join(plans, "\n\n"), nil
+}
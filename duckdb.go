@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"strings"
+)
+
+const (
+	// duckdbImage is the container image of the DuckDB CLI that runs the queries
+	duckdbImage = "duckdb/duckdb:1.2.2"
+	// duckdbPath is where the DuckDB database file is placed inside the container
+	duckdbPath = "/db/database.duckdb"
+	// duckdbDataPath is where data artifacts such as Parquet and CSV files are mounted
+	duckdbDataPath = "/data"
+)
+
+// Use DuckDB, optionally backed by a database file, with data files mounted at /data. Queries run with the DuckDB CLI
+// in a container instead of a database/sql driver, so only run-query without a format, limit, offset or columns,
+// run-read-query and run-queries-parallel are supported, and changes to the database file are not kept
+func (m *Sql) WithDuckdb(
+	// DuckDB database file, an in-memory database is used when omitted
+	// +optional
+	database *dagger.File,
+	// Directory of Parquet, CSV or JSON files to query, mounted at /data
+	// +optional
+	data *dagger.Directory,
+) *Sql {
+	m.Duckdb = true
+	m.DuckdbFile = database
+	m.DuckdbData = data
+	return m
+}
+
+// duckdbQuery runs a query with the DuckDB CLI and returns the rows in comma-separated format
+func (m *Sql) duckdbQuery(ctx context.Context, query string, timeoutSeconds int) (string, error) {
+	if err := m.checkPolicy("duckdb", query); err != nil {
+		return "", err
+	}
+
+	ctr := dag.Container().From(duckdbImage).WithWorkdir(duckdbDataPath)
+	if m.DuckdbData != nil {
+		ctr = ctr.WithMountedDirectory(duckdbDataPath, m.DuckdbData)
+	}
+
+	args := []string{"duckdb", "-csv", "-noheader"}
+	if m.DuckdbFile != nil {
+		ctr = ctr.WithFile(duckdbPath, m.DuckdbFile)
+		args = append(args, duckdbPath)
+	}
+	args = append(args, "-c", query)
+
+	ctx, cancel := m.queryContext(ctx, timeoutSeconds)
+	defer cancel()
+
+	out, err := ctr.WithExec(args).Stdout(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error querying database: %w", err)
+	}
+
+	out = strings.TrimSuffix(out, "\n")
+	if out == "" {
+		return "", fmt.Errorf("no results found")
+	}
+
+	return out, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"strings"
+)
+
+// Use the connection string from a dotenv file, e.g. the .env used for local development
+func (m *Sql) FromEnvFile(
+	ctx context.Context,
+	file *dagger.File,
+	// Name of the variable holding the connection string
+	// +default="DATABASE_URL"
+	key string,
+) (*Sql, error) {
+	contents, err := file.Contents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading env file: %w", err)
+	}
+
+	vars, err := parseEnv(contents)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing env file: %w", err)
+	}
+
+	conn, ok := vars[key]
+	if !ok || conn == "" {
+		return nil, fmt.Errorf("%s is not set in the env file", key)
+	}
+
+	// include a hash of the value in the name so different connection strings never share a secret
+	m.Conn = dag.SetSecret(fmt.Sprintf("env-%s-%x", key, sha256.Sum256([]byte(conn))), conn)
+
+	return m, nil
+}
+
+// parseEnv parses KEY=VALUE lines of a dotenv file, supporting comments, export prefixes and quoted values
+func parseEnv(contents string) (map[string]string, error) {
+	vars := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]:
+			value = value[1 : len(value)-1]
+		default:
+			// unquoted values end at an inline comment
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		vars[key] = value
+	}
+
+	return vars, scanner.Err()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// mermaidPlain matches the entity names mermaid accepts without quotes
+	mermaidPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	// mermaidInvalid matches the characters mermaid does not accept in attribute names and types
+	mermaidInvalid = regexp.MustCompile(`[^A-Za-z0-9_\-()\[\]]+`)
+)
+
+// Generate a mermaid erDiagram of the tables in a schema with their columns, keys and foreign key relationships
+func (m *Sql) ErDiagram(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+) (string, error) {
+	description, err := m.DescribeSchema(ctx, schema)
+	if err != nil {
+		return "", err
+	}
+	keys, primaryKeys, err := m.schemaKeys(ctx, schema, description)
+	if err != nil {
+		return "", err
+	}
+
+	// a column is a foreign key when any foreign key of its table has it
+	foreign := map[string]bool{}
+	for _, key := range keys {
+		for _, column := range key.Columns {
+			foreign[key.Table+"."+column] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, table := range description.Tables {
+		fmt.Fprintf(&b, "    %s {\n", mermaidEntity(table.Name))
+		for _, column := range table.Columns {
+			var markers []string
+			if primaryKeys[table.Name][column.Name] {
+				markers = append(markers, "PK")
+			}
+			if foreign[table.Name+"."+column.Name] {
+				markers = append(markers, "FK")
+			}
+			fmt.Fprintf(&b, "        %s %s", mermaidAttribute(column.DataType), mermaidAttribute(column.Name))
+			if len(markers) > 0 {
+				fmt.Fprintf(&b, " %s", strings.Join(markers, ", "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("    }\n")
+	}
+
+	nullable := map[string]bool{}
+	for _, table := range description.Tables {
+		for _, column := range table.Columns {
+			nullable[table.Name+"."+column.Name] = column.IsNullable
+		}
+	}
+	for _, key := range keys {
+		// a row of the referencing table needs a referenced row unless a column of its key can be NULL
+		parent := "||"
+		for _, column := range key.Columns {
+			if nullable[key.Table+"."+column] {
+				parent = "|o"
+			}
+		}
+		fmt.Fprintf(&b, "    %s %s--o{ %s : %q\n", mermaidEntity(key.ReferencedTable), parent, mermaidEntity(key.Table), key.Name)
+	}
+
+	return b.String(), nil
+}
+
+// schemaKeys returns the foreign keys of a schema and the primary key columns of its tables, both are empty for
+// databases that cannot list them
+func (m *Sql) schemaKeys(ctx context.Context, schema string, description *SchemaDescription) ([]*ForeignKey, map[string]map[string]bool, error) {
+	_, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	keys := []*ForeignKey{}
+	if _, ok := d.(foreignKeyLister); ok {
+		if keys, err = m.ListForeignKeys(ctx, schema); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	primaryKeys := map[string]map[string]bool{}
+	if _, ok := d.(primaryKeyLister); ok {
+		for _, table := range description.Tables {
+			columns, err := m.PrimaryKey(ctx, keyTable(d, schema, table.Name))
+			if err != nil {
+				return nil, nil, err
+			}
+			primaryKeys[table.Name] = map[string]bool{}
+			for _, column := range columns {
+				primaryKeys[table.Name][column] = true
+			}
+		}
+	}
+
+	return keys, primaryKeys, nil
+}
+
+// keyTable returns the name PrimaryKey, ListIndexes and ListConstraints find a table of a schema by, postgres and SQL
+// Server resolve it as an identifier while the others compare it with the names in their catalogs
+func keyTable(d dialect, schema, table string) string {
+	if (d.Engine() == "postgres" && d.Name() != "redshift") || d.Engine() == "sqlserver" {
+		return qualifiedTable(d, schema, table)
+	}
+
+	return table
+}
+
+// mermaidEntity returns a table name as a mermaid entity, quoted when it has characters mermaid does not accept
+func mermaidEntity(name string) string {
+	if mermaidPlain.MatchString(name) {
+		return name
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, "'") + `"`
+}
+
+// mermaidAttribute replaces the characters mermaid does not accept in attribute names and types, e.g. the space of
+// character varying
+func mermaidAttribute(s string) string {
+	s = strings.Trim(mermaidInvalid.ReplaceAllString(s, "_"), "_")
+	if s == "" || !mermaidPlain.MatchString(s[:1]) {
+		s = "_" + s
+	}
+
+	return s
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ExecResult represents the outcome of statements that do not return rows
+type ExecResult struct {
+	RowsAffected int
+	// LastInsertID is 0 for databases that do not report it, such as postgres
+	LastInsertID int
+	// Plan is what the statements would do, only set for a dry run
+	Plan string
+	// RolledBack lists the optional statements (1-based) that failed and were rolled back to their savepoint
+	RolledBack []int
+	// DurationMs is how long the statements took to run, in milliseconds
+	DurationMs int
+	// Notices are the notices (postgres) and warnings (mysql) the server raised while running the statements
+	Notices []string
+}
+
+// Execute a statement such as CREATE TABLE, INSERT, UPDATE or DELETE and return the rows it affected (use with-sqlite-statement to keep changes to a SQLite file)
+func (m *Sql) Exec(
+	ctx context.Context,
+	statement string,
+	// Cancel the statement on the server after this many seconds, overriding the statement timeout
+	// +optional
+	timeoutSeconds int,
+	// Show the plan of the statement instead of running it
+	// +optional
+	dryRun bool,
+) (*ExecResult, error) {
+	if m.Duckdb {
+		return nil, fmt.Errorf("exec is not supported for duckdb, use run-query")
+	}
+	if dryRun {
+		plan, err := m.dryRun(ctx, []string{statement})
+		if err != nil {
+			return nil, err
+		}
+		return &ExecResult{Plan: plan}, nil
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := m.checkPolicy(d.Engine(), statement); err != nil {
+		return nil, err
+	}
+	ctx, cancel := m.queryContext(ctx, timeoutSeconds)
+	defer cancel()
+
+	start := time.Now()
+	m.notices.drain()
+
+	var result sql.Result
+	var warnings []string
+	err = m.retryStatement(ctx, d, func() error {
+		conn, release, err := serverConn(ctx, db, d)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		if result, err = conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error executing statement: %w", err)
+		}
+		// the statement already ran, so failing to read its warnings must not retry it
+		if warnings, err = serverWarnings(ctx, conn, d); err != nil {
+			warnings = []string{err.Error()}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// drivers return an error when they cannot report a value, which is not a failure of the statement
+	affected, _ := result.RowsAffected()
+	id, _ := result.LastInsertId()
+
+	return &ExecResult{
+		RowsAffected: int(affected),
+		LastInsertID: int(id),
+		DurationMs:   int(time.Since(start).Milliseconds()),
+		Notices:      append(m.notices.drain(), warnings...),
+	}, nil
+}
+
+// Execute statements in a single transaction, rolling back every change when a required statement fails
+func (m *Sql) RunTransaction(
+	ctx context.Context,
+	statements []string,
+	// Positions (1-based) of statements that run inside a savepoint, a failure only rolls back that statement
+	// +optional
+	optional []int,
+) (*ExecResult, error) {
+	if m.Duckdb {
+		return nil, fmt.Errorf("transactions are not supported for duckdb")
+	}
+
+	savepoints := make(map[int]bool, len(optional))
+	for _, position := range optional {
+		if position < 1 || position > len(statements) {
+			return nil, fmt.Errorf("optional statement %d does not exist", position)
+		}
+		savepoints[position-1] = true
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := m.checkPolicy(d.Engine(), statements...); err != nil {
+		return nil, err
+	}
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	start := time.Now()
+	m.notices.drain()
+
+	// a rolled back transaction can safely run again, e.g. after a serialization failure, but once COMMIT was sent
+	// the server may have applied it before the error, so a failed commit is never retried
+	var total *ExecResult
+	var committing bool
+	err = m.retryIf(ctx, func(err error) bool { return !committing && retryableStatement(d, err) }, func() error {
+		conn, release, err := serverConn(ctx, db, d)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("error starting transaction: %w", err)
+		}
+		// a no-op once the transaction is committed
+		defer tx.Rollback()
+
+		if total, err = execSavepoints(ctx, tx, d, statements, savepoints); err != nil {
+			return fmt.Errorf("transaction rolled back: %w", err)
+		}
+
+		committing = true
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing transaction: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	total.DurationMs = int(time.Since(start).Milliseconds())
+	total.Notices = append(m.notices.drain(), total.Notices...)
+
+	return total, nil
+}
+
+// execer is implemented by both connections and transactions
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// runStatements checks statements against the policy and executes them in order within the statement timeout
+func (m *Sql) runStatements(ctx context.Context, db *sql.DB, d dialect, statements []string) (*ExecResult, error) {
+	if err := m.checkPolicy(d.Engine(), statements...); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	conn, release, err := serverConn(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return execStatements(ctx, conn, statements)
+}
+
+// execStatements executes statements in order and sums the rows they affected
+func execStatements(ctx context.Context, e execer, statements []string) (*ExecResult, error) {
+	total := &ExecResult{}
+	for i, statement := range statements {
+		result, err := e.ExecContext(ctx, statement)
+		if err != nil {
+			return nil, fmt.Errorf("error executing statement %d: %w", i+1, err)
+		}
+		total.add(result)
+	}
+
+	return total, nil
+}
+
+// add sums the rows affected by a statement and keeps its insert id when the driver reports one
+func (r *ExecResult) add(result sql.Result) {
+	affected, _ := result.RowsAffected()
+	r.RowsAffected += int(affected)
+	if id, err := result.LastInsertId(); err == nil {
+		r.LastInsertID = int(id)
+	}
+}
+
+// savepointStatements returns the statements that create, roll back to and release a savepoint, release is empty when the
+// engine releases savepoints with the transaction
+func savepointStatements(d dialect, name string) (string, string, string, error) {
+	switch d.Engine() {
+	case "postgres", "mysql", "sqlite":
+		return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, "RELEASE SAVEPOINT " + name, nil
+	case "oracle":
+		return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, "", nil
+	case "sqlserver":
+		return "SAVE TRANSACTION " + name, "ROLLBACK TRANSACTION " + name, "", nil
+	}
+
+	return "", "", "", fmt.Errorf("savepoints are not supported for %s", d.Name())
+}
+
+// execSavepoints executes statements in a transaction, running the optional ones inside a savepoint so their failure
+// only rolls back their own changes
+func execSavepoints(ctx context.Context, tx *sql.Tx, d dialect, statements []string, optional map[int]bool) (*ExecResult, error) {
+	total := &ExecResult{}
+	for i, statement := range statements {
+		if !optional[i] {
+			result, err := tx.ExecContext(ctx, statement)
+			if err != nil {
+				return nil, fmt.Errorf("error executing statement %d: %w", i+1, err)
+			}
+			total.add(result)
+			if err := total.warnings(ctx, tx, d, i); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		save, rollback, release, err := savepointStatements(d, fmt.Sprintf("step_%d", i+1))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, save); err != nil {
+			return nil, fmt.Errorf("error creating savepoint for statement %d: %w", i+1, err)
+		}
+
+		result, err := tx.ExecContext(ctx, statement)
+		if err != nil {
+			if _, err := tx.ExecContext(ctx, rollback); err != nil {
+				return nil, fmt.Errorf("error rolling back statement %d: %w", i+1, err)
+			}
+			total.RolledBack = append(total.RolledBack, i+1)
+			continue
+		}
+		total.add(result)
+		if err := total.warnings(ctx, tx, d, i); err != nil {
+			return nil, err
+		}
+
+		if release != "" {
+			if _, err := tx.ExecContext(ctx, release); err != nil {
+				return nil, fmt.Errorf("error releasing savepoint for statement %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// txDriver is a driver whose statements and commits fail with the given errors, counting the statements it executes
+type txDriver struct {
+	execErr   error
+	commitErr error
+	execs     int
+}
+
+func (d *txDriver) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *txDriver) Driver() driver.Driver                        { return d }
+func (d *txDriver) Open(string) (driver.Conn, error)             { return d, nil }
+func (d *txDriver) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (d *txDriver) Close() error                                 { return nil }
+func (d *txDriver) Begin() (driver.Tx, error)                    { return d, nil }
+func (d *txDriver) Commit() error                                { return d.commitErr }
+func (d *txDriver) Rollback() error                              { return nil }
+
+func (d *txDriver) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	d.execs++
+	if d.execErr != nil {
+		return nil, d.execErr
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func TestRunTransactionRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		execErr   error
+		commitErr error
+		wantExecs int
+	}{
+		{name: "committed", wantExecs: 1},
+		{name: "serialization failure", execErr: &pgconn.PgError{Code: "40001"}, wantExecs: 3},
+		{name: "syntax error", execErr: &pgconn.PgError{Code: "42601"}, wantExecs: 1},
+		// the server may have committed before the error, so the transaction must not run again
+		{name: "commit on a bad connection", commitErr: driver.ErrBadConn, wantExecs: 1},
+		{name: "commit serialization failure", commitErr: &pgconn.PgError{Code: "40001"}, wantExecs: 1},
+		{name: "connection lost during commit", commitErr: io.ErrUnexpectedEOF, wantExecs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &txDriver{execErr: tt.execErr, commitErr: tt.commitErr}
+			db := sql.OpenDB(fake)
+			defer db.Close()
+
+			m := &Sql{RetryAttempts: 2, db: db, dbDialect: postgresDialect{}}
+			_, err := m.RunTransaction(context.Background(), []string{"UPDATE accounts SET balance = balance - 1"}, nil)
+			if fails := tt.execErr != nil || tt.commitErr != nil; (err != nil) != fails {
+				t.Fatalf("RunTransaction() error = %v, want error %v", err, fails)
+			}
+			if fake.execs != tt.wantExecs {
+				t.Errorf("ran the transaction %d times, want %d", fake.execs, tt.wantExecs)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// explainer is implemented by dialects that can show the plan of a statement
+type explainer interface {
+	// ExplainQuery returns a statement that shows the plan of another in a format such as text or json,
+	// analyze runs the statement to report actual timings and row counts
+	ExplainQuery(statement string, analyze bool, format string) (string, error)
+}
+
+// Show the plan of a query, analyze runs the query to include actual timings and row counts
+func (m *Sql) Explain(
+	ctx context.Context,
+	query string,
+	// Run the query and report what it actually did, which applies any changes it makes
+	// +optional
+	analyze bool,
+	// Format of the plan, text or json (postgres also supports yaml and xml)
+	// +default="text"
+	format string,
+) (string, error) {
+	if m.Duckdb {
+		return "", fmt.Errorf("explain is not supported for duckdb, use run-query with EXPLAIN")
+	}
+
+	_, d, _, err := m.connect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	if err := m.checkPolicy(d.Engine(), query); err != nil {
+		return "", err
+	}
+
+	e, ok := d.(explainer)
+	if !ok {
+		return "", fmt.Errorf("explain is not supported for %s", d.Name())
+	}
+
+	explain, err := e.ExplainQuery(query, analyze, strings.ToLower(format))
+	if err != nil {
+		return "", err
+	}
+
+	result, err := m.fetch(ctx, explain, queryOptions{internal: true})
+	if err != nil {
+		return "", err
+	}
+
+	return result.csv(""), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ForeignKey represents a foreign key constraint between two tables
+type ForeignKey struct {
+	Name    string
+	Table   string
+	Columns []string
+	// ReferencedTable is qualified with its schema when it is in another schema
+	ReferencedTable string
+	// ReferencedColumns are in the order of the columns they match
+	ReferencedColumns []string
+	// OnDelete and OnUpdate are the referential actions, e.g. NO ACTION, CASCADE or SET NULL
+	OnDelete string
+	OnUpdate string
+}
+
+// foreignKeyLister is implemented by dialects that can list the foreign keys of a schema
+type foreignKeyLister interface {
+	// ForeignKeysQuery lists the constraint name, table, column, referenced table, referenced column and the on delete
+	// and on update actions of every column of the foreign keys in a schema, ordered by table, constraint and position
+	ForeignKeysQuery(database, schema string) (string, error)
+}
+
+// List the foreign keys of the tables in a schema with the columns and tables they reference
+func (m *Sql) ListForeignKeys(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+) ([]*ForeignKey, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(foreignKeyLister)
+	if !ok {
+		return nil, fmt.Errorf("listing foreign keys is not supported for %s", d.Name())
+	}
+	query, err := l.ForeignKeysQuery(database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*ForeignKey{}
+	var key *ForeignKey
+	for rows.Next() {
+		var name, table, column, referencedTable, onDelete, onUpdate string
+		// SQLite leaves out the referenced column when it is the primary key
+		var referencedColumn sql.NullString
+		if err := rows.Scan(&name, &table, &column, &referencedTable, &referencedColumn, &onDelete, &onUpdate); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by table and constraint, so a new pair starts the next key
+		if key == nil || key.Table != table || key.Name != name {
+			key = &ForeignKey{
+				Name:            name,
+				Table:           table,
+				ReferencedTable: referencedTable,
+				OnDelete:        referentialAction(onDelete),
+				OnUpdate:        referentialAction(onUpdate),
+			}
+			keys = append(keys, key)
+		}
+		key.Columns = append(key.Columns, column)
+		key.ReferencedColumns = append(key.ReferencedColumns, referencedColumn.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// referentialAction normalizes the name of a referential action, e.g. SET_NULL from sqlserver is SET NULL
+func referentialAction(action string) string {
+	return strings.ToUpper(strings.ReplaceAll(action, "_", " "))
+}
@@ -0,0 +1,779 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// resultPath is where query results are written inside the module workdir before they are returned as a file, with the
+// extension of the format
+const resultPath = "results"
+
+// formatExtensions maps the output formats whose files do not use their name as the extension to the extension
+var formatExtensions = map[string]string{
+	"html-styled": "html",
+	"inserts":     "sql",
+	"markdown":    "md",
+	"table":       "txt",
+}
+
+// rowWriter writes query results in an output format as rows are read
+type rowWriter interface {
+	// header receives the columns before any row
+	header(columns []*sql.ColumnType) error
+	row(values []any) error
+	// flush writes anything still buffered once every row is read
+	flush() error
+}
+
+// formatOptions configures the writer of an output format
+type formatOptions struct {
+	// table is the target table of the inserts format
+	table string
+	// null is the text of NULL in formats that write values as text, formats with a null of their own keep it
+	null string
+	// delimiter and recordDelimiter replace the comma and line break of the csv format, escapes are read as in Go
+	delimiter       string
+	recordDelimiter string
+}
+
+// formats maps the name of an output format to a constructor of its writer
+var formats = map[string]func(w io.Writer, opts formatOptions) rowWriter{
+	"csv":         newCSVWriter,
+	"html":        newHTMLWriter,
+	"html-styled": newStyledHTMLWriter,
+	"inserts":     newInsertsWriter,
+	"json":        newJSONWriter,
+	"jsonl":       newJSONLWriter,
+	"markdown":    newMarkdownWriter,
+	"table":       newTableWriter,
+	"tsv":         newTSVWriter,
+	"vertical":    newVerticalWriter,
+	"yaml":        newYAMLWriter,
+}
+
+// fileFormats maps the name of a binary output format, which is only written to files, to a constructor of its writer
+var fileFormats = map[string]func(w io.Writer, opts formatOptions) rowWriter{
+	"arrow":   newArrowWriter,
+	"avro":    newAvroWriter,
+	"parquet": newParquetWriter,
+	"xlsx":    newXLSXWriter,
+}
+
+// formatWriter returns the constructor of the writer of an output format
+func formatWriter(format string) (func(w io.Writer, opts formatOptions) rowWriter, error) {
+	newWriter, ok := formats[strings.ToLower(format)]
+	if !ok {
+		names := make([]string, 0, len(formats))
+		for name := range formats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("unsupported format %q, supported formats are %s", format, strings.Join(names, ", "))
+	}
+
+	return newWriter, nil
+}
+
+// formatted runs a query and returns the results in an output format
+func (m *Sql) formatted(ctx context.Context, query, format string, opts queryOptions) (string, error) {
+	var b strings.Builder
+	newWriter, err := formatWriter(format)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := m.stream(ctx, query, opts, newWriter(&b, opts.output)); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// Query the database and stream the results into a file named after the format, e.g. results.csv, which keeps large or
+// binary result sets out of memory
+func (m *Sql) QueryToFile(
+	ctx context.Context,
+	query string,
+	// Output format of the file, the formats of RunQuery, arrow (an IPC stream), avro (an object container file), parquet or xlsx
+	// +default="csv"
+	format string,
+	// Fetch postgres rows through a server-side cursor in batches of this size, mysql always streams rows
+	// +optional
+	batchSize int,
+	// Target table of the inserts format
+	// +optional
+	table string,
+	// Cancel the query on the server after this many seconds, overriding the statement timeout
+	// +optional
+	timeoutSeconds int,
+	// Maximum number of rows to write
+	// +optional
+	limit int,
+	// Number of rows to skip
+	// +optional
+	offset int,
+	// JSON array of values bound to the placeholders of the query in order, e.g. [42, "name"]
+	// +optional
+	args string,
+	// Text of NULL values in formats that write values as text, e.g. NULL or \N, json, yaml and inserts keep their own
+	// null and it is empty by default
+	// +optional
+	null string,
+	// Columns to write in this order, every column of the query by default
+	// +optional
+	columns []string,
+	// Columns to leave out of the file
+	// +optional
+	exclude []string,
+	// Field delimiter of the csv format instead of a comma, e.g. | or \x01
+	// +optional
+	delimiter string,
+	// Record delimiter of the csv format instead of a line break, e.g. \n or \x02
+	// +optional
+	recordDelimiter string,
+	// Gzip the file, adding .gz to its name
+	// +optional
+	compress bool,
+) (*dagger.File, error) {
+	format = strings.ToLower(format)
+	newWriter, ok := fileFormats[format]
+	if !ok {
+		var err error
+		if newWriter, err = formatWriter(format); err != nil {
+			return nil, err
+		}
+	}
+
+	var values []any
+	if args != "" {
+		var err error
+		if values, err = jsonArgs(args); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := queryOptions{
+		timeoutSeconds: timeoutSeconds,
+		limit:          limit,
+		offset:         offset,
+		batchSize:      batchSize,
+		columns:        columns,
+		exclude:        exclude,
+		output: formatOptions{
+			table:           table,
+			null:            null,
+			delimiter:       delimiter,
+			recordDelimiter: recordDelimiter,
+		},
+	}
+
+	return m.queryToFile(ctx, query, format, compress, opts, newWriter, values...)
+}
+
+// queryToFile streams the results of a query into a file with a writer, the file extension is that of the format
+func (m *Sql) queryToFile(ctx context.Context, query, format string, compress bool, opts queryOptions, newWriter func(w io.Writer, opts formatOptions) rowWriter, args ...any) (*dagger.File, error) {
+	extension, ok := formatExtensions[format]
+	if !ok {
+		extension = format
+	}
+
+	f, err := createResultFile(resultPath+"."+extension, compress)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := m.stream(ctx, query, opts, newWriter(f, opts.output), args...); err != nil {
+		return nil, err
+	}
+
+	return f.file(ctx)
+}
+
+// columnNames returns the name of every column
+func columnNames(columns []*sql.ColumnType) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name()
+	}
+
+	return names
+}
+
+// textCells converts the values of a row into cells for formats that write values as text, with NULL as the null text
+func textCells(columns []*ResultColumn, values []any, null string) []*ResultCell {
+	cells := typedCells(columns, values)
+	for _, cell := range cells {
+		if cell.Null {
+			cell.Value = null
+		}
+	}
+
+	return cells
+}
+
+// csvWriter writes RFC 4180 CSV with the column names as the first record, quoting values that contain commas, quotes
+// or line breaks
+type csvWriter struct {
+	w       *csv.Writer
+	columns []*ResultColumn
+	null    string
+}
+
+func newCSVWriter(w io.Writer, opts formatOptions) rowWriter {
+	if opts.delimiter != "" || opts.recordDelimiter != "" {
+		return &delimitedWriter{w: w, delimiter: opts.delimiter, recordDelimiter: opts.recordDelimiter, null: opts.null}
+	}
+
+	c := csv.NewWriter(w)
+	c.UseCRLF = true
+	return &csvWriter{w: c, null: opts.null}
+}
+
+func (c *csvWriter) header(columns []*sql.ColumnType) error {
+	c.columns = resultColumns(columns)
+	return c.w.Write(columnNames(columns))
+}
+
+func (c *csvWriter) row(values []any) error {
+	cells := textCells(c.columns, values, c.null)
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cell.Value
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// delimitedWriter writes csv with custom delimiters, quoting values that contain a delimiter, quotes or line breaks
+type delimitedWriter struct {
+	w               io.Writer
+	delimiter       string
+	recordDelimiter string
+	columns         []*ResultColumn
+	null            string
+}
+
+func (d *delimitedWriter) header(columns []*sql.ColumnType) error {
+	var err error
+	if d.delimiter, err = unescapeDelimiter(d.delimiter, ","); err != nil {
+		return err
+	}
+	if d.recordDelimiter, err = unescapeDelimiter(d.recordDelimiter, "\r\n"); err != nil {
+		return err
+	}
+	if d.delimiter == d.recordDelimiter {
+		return fmt.Errorf("the field and record delimiters must differ")
+	}
+
+	d.columns = resultColumns(columns)
+	names := columnNames(columns)
+	for i, name := range names {
+		names[i] = d.quote(name)
+	}
+
+	return d.record(names)
+}
+
+func (d *delimitedWriter) row(values []any) error {
+	cells := typedCells(d.columns, values)
+	fields := make([]string, len(cells))
+	for i, cell := range cells {
+		if cell.Null {
+			fields[i] = d.null
+			continue
+		}
+		fields[i] = d.quote(cell.Value)
+	}
+
+	return d.record(fields)
+}
+
+// quote quotes a value that would otherwise be read as more than one field or record
+func (d *delimitedWriter) quote(value string) string {
+	if !strings.ContainsAny(value, "\"\r\n") && !strings.Contains(value, d.delimiter) && !strings.Contains(value, d.recordDelimiter) {
+		return value
+	}
+
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+func (d *delimitedWriter) record(fields []string) error {
+	_, err := io.WriteString(d.w, strings.Join(fields, d.delimiter)+d.recordDelimiter)
+	return err
+}
+
+func (d *delimitedWriter) flush() error { return nil }
+
+// unescapeDelimiter reads the escapes of a delimiter as in a Go string, e.g. \t or \x01
+func unescapeDelimiter(delimiter, fallback string) (string, error) {
+	if delimiter == "" {
+		return fallback, nil
+	}
+
+	unescaped, err := strconv.Unquote(`"` + strings.ReplaceAll(delimiter, `"`, `\"`) + `"`)
+	if err != nil {
+		return "", fmt.Errorf("invalid delimiter %q: %w", delimiter, err)
+	}
+	if unescaped == "" || strings.Contains(unescaped, `"`) {
+		return "", fmt.Errorf("invalid delimiter %q, delimiters cannot be empty or contain quotes", delimiter)
+	}
+
+	return unescaped, nil
+}
+
+// jsonWriter writes a JSON array with an object per row, keyed by column name in column order
+type jsonWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	rows    int
+}
+
+func newJSONWriter(w io.Writer, _ formatOptions) rowWriter {
+	return &jsonWriter{w: w}
+}
+
+func (j *jsonWriter) header(columns []*sql.ColumnType) error {
+	j.columns = resultColumns(columns)
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonWriter) row(values []any) error {
+	object, err := jsonObject(j.columns, typedCells(j.columns, values))
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n"
+	if j.rows == 0 {
+		separator = "\n"
+	}
+	j.rows++
+	_, err = io.WriteString(j.w, separator+string(object))
+	return err
+}
+
+func (j *jsonWriter) flush() error {
+	end := "]"
+	if j.rows > 0 {
+		end = "\n]"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+// htmlStyle is the stylesheet of the html-styled format
+const htmlStyle = `<style>
+table.results { border-collapse: collapse; font-family: sans-serif; font-size: 14px; }
+table.results th, table.results td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+table.results th { background: #f6f8fa; }
+table.results td.number { text-align: right; }
+table.results tbody tr:nth-child(even) { background: #fafbfc; }
+</style>
+`
+
+// htmlWriter writes a table element that can be embedded in a page, numbers have the number class
+type htmlWriter struct {
+	w       io.Writer
+	style   bool
+	columns []*ResultColumn
+	null    string
+}
+
+func newHTMLWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &htmlWriter{w: w, null: opts.null}
+}
+
+func newStyledHTMLWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &htmlWriter{w: w, style: true, null: opts.null}
+}
+
+func (h *htmlWriter) header(columns []*sql.ColumnType) error {
+	h.columns = resultColumns(columns)
+
+	var b strings.Builder
+	if h.style {
+		b.WriteString(htmlStyle)
+	}
+	b.WriteString("<table class=\"results\">\n<thead>\n<tr>")
+	for _, name := range columnNames(columns) {
+		b.WriteString("<th>" + html.EscapeString(name) + "</th>")
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) row(values []any) error {
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, cell := range textCells(h.columns, values, h.null) {
+		switch cell.Type {
+		case "integer", "decimal", "float":
+			b.WriteString("<td class=\"number\">")
+		default:
+			b.WriteString("<td>")
+		}
+		b.WriteString(html.EscapeString(cell.Value) + "</td>")
+	}
+	b.WriteString("</tr>\n")
+
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) flush() error {
+	_, err := io.WriteString(h.w, "</tbody>\n</table>\n")
+	return err
+}
+
+// jsonlWriter writes JSON Lines, an object per row on its own line, so the output can be read as it streams
+type jsonlWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+}
+
+func newJSONLWriter(w io.Writer, _ formatOptions) rowWriter {
+	return &jsonlWriter{w: w}
+}
+
+func (j *jsonlWriter) header(columns []*sql.ColumnType) error {
+	j.columns = resultColumns(columns)
+	return nil
+}
+
+func (j *jsonlWriter) row(values []any) error {
+	object, err := jsonObject(j.columns, typedCells(j.columns, values))
+	if err != nil {
+		return err
+	}
+
+	_, err = j.w.Write(append(object, '\n'))
+	return err
+}
+
+func (j *jsonlWriter) flush() error { return nil }
+
+// tsvEscaper escapes the characters that would break the fields and lines of TSV, as in the postgres text format
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// tsvWriter writes tab-separated values with the column names as the first line
+type tsvWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	null    string
+}
+
+func newTSVWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &tsvWriter{w: w, null: opts.null}
+}
+
+func (t *tsvWriter) header(columns []*sql.ColumnType) error {
+	t.columns = resultColumns(columns)
+	names := columnNames(columns)
+	for i, name := range names {
+		names[i] = tsvEscaper.Replace(name)
+	}
+
+	return t.line(names)
+}
+
+func (t *tsvWriter) row(values []any) error {
+	cells := typedCells(t.columns, values)
+	fields := make([]string, len(cells))
+	for i, cell := range cells {
+		// the null text is written as is, e.g. \N for postgres
+		if cell.Null {
+			fields[i] = t.null
+			continue
+		}
+		fields[i] = tsvEscaper.Replace(cell.Value)
+	}
+
+	return t.line(fields)
+}
+
+func (t *tsvWriter) line(fields []string) error {
+	_, err := io.WriteString(t.w, strings.Join(fields, "\t")+"\n")
+	return err
+}
+
+func (t *tsvWriter) flush() error { return nil }
+
+// markdownEscaper escapes the characters that would break the cells of a Markdown table
+var markdownEscaper = strings.NewReplacer(`|`, `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// markdownWriter writes a GitHub-flavored Markdown table
+type markdownWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	null    string
+}
+
+func newMarkdownWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &markdownWriter{w: w, null: opts.null}
+}
+
+func (md *markdownWriter) header(columns []*sql.ColumnType) error {
+	md.columns = resultColumns(columns)
+	if err := md.line(columnNames(columns)); err != nil {
+		return err
+	}
+
+	separators := make([]string, len(columns))
+	for i, column := range md.columns {
+		// right-align numbers like a spreadsheet would
+		switch databaseKind(column.DatabaseType) {
+		case "integer", "decimal", "float":
+			separators[i] = "---:"
+		default:
+			separators[i] = "---"
+		}
+	}
+	_, err := io.WriteString(md.w, "| "+strings.Join(separators, " | ")+" |\n")
+	return err
+}
+
+func (md *markdownWriter) row(values []any) error {
+	cells := textCells(md.columns, values, md.null)
+	fields := make([]string, len(cells))
+	for i, cell := range cells {
+		fields[i] = cell.Value
+	}
+
+	return md.line(fields)
+}
+
+func (md *markdownWriter) line(fields []string) error {
+	for i, field := range fields {
+		fields[i] = markdownEscaper.Replace(field)
+	}
+	_, err := io.WriteString(md.w, "| "+strings.Join(fields, " | ")+" |\n")
+	return err
+}
+
+func (md *markdownWriter) flush() error { return nil }
+
+// tableWriter writes an aligned table for terminals like the default display of psql, it holds every row because the
+// width of a column is only known once all of them are read
+type tableWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	names   []string
+	rows    [][]*ResultCell
+	null    string
+}
+
+func newTableWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &tableWriter{w: w, null: opts.null}
+}
+
+func (t *tableWriter) header(columns []*sql.ColumnType) error {
+	t.columns = resultColumns(columns)
+	t.names = columnNames(columns)
+	return nil
+}
+
+func (t *tableWriter) row(values []any) error {
+	cells := typedCells(t.columns, values)
+	for _, cell := range cells {
+		if cell.Null {
+			cell.Value = t.null
+			continue
+		}
+		// keep every row on a single line
+		cell.Value = tsvEscaper.Replace(cell.Value)
+	}
+	t.rows = append(t.rows, cells)
+	return nil
+}
+
+func (t *tableWriter) flush() error {
+	widths := make([]int, len(t.names))
+	for i, name := range t.names {
+		widths[i] = utf8.RuneCountInString(name)
+	}
+	for _, cells := range t.rows {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell.Value))
+		}
+	}
+
+	var b strings.Builder
+	separators := make([]string, len(widths))
+	for i, name := range t.names {
+		if i > 0 {
+			b.WriteString("|")
+		}
+		// center the column names
+		padding := widths[i] - utf8.RuneCountInString(name)
+		b.WriteString(" " + strings.Repeat(" ", padding/2) + name + strings.Repeat(" ", padding-padding/2) + " ")
+		separators[i] = strings.Repeat("-", widths[i]+2)
+	}
+	b.WriteString("\n" + strings.Join(separators, "+") + "\n")
+
+	for _, cells := range t.rows {
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell.Value))
+			// right-align numbers like psql does
+			switch cell.Type {
+			case "integer", "decimal", "float":
+				b.WriteString(" " + padding + cell.Value + " ")
+			default:
+				b.WriteString(" " + cell.Value + padding + " ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	footer := fmt.Sprintf("(%d rows)\n", len(t.rows))
+	if len(t.rows) == 1 {
+		footer = "(1 row)\n"
+	}
+	b.WriteString(footer)
+
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+// verticalWriter writes every row as a block of column: value lines like the \G terminator of mysql, which keeps wide
+// rows readable
+type verticalWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	names   []string
+	null    string
+	rows    int
+}
+
+func newVerticalWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &verticalWriter{w: w, null: opts.null}
+}
+
+func (v *verticalWriter) header(columns []*sql.ColumnType) error {
+	v.columns = resultColumns(columns)
+	v.names = columnNames(columns)
+
+	// right-align the names so the values line up
+	width := 0
+	for _, name := range v.names {
+		width = max(width, utf8.RuneCountInString(name))
+	}
+	for i, name := range v.names {
+		v.names[i] = strings.Repeat(" ", width-utf8.RuneCountInString(name)) + name
+	}
+
+	return nil
+}
+
+func (v *verticalWriter) row(values []any) error {
+	v.rows++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*************************** %d. row ***************************\n", v.rows)
+	for i, cell := range textCells(v.columns, values, v.null) {
+		b.WriteString(v.names[i] + ": " + cell.Value + "\n")
+	}
+
+	_, err := io.WriteString(v.w, b.String())
+	return err
+}
+
+func (v *verticalWriter) flush() error { return nil }
+
+// yamlPlain matches strings that read back as the same string when written unquoted in YAML
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*( [A-Za-z0-9_./-]+)*$`)
+
+// yamlWriter writes a YAML sequence with a mapping per row, keyed by column name in column order
+type yamlWriter struct {
+	w       io.Writer
+	columns []*ResultColumn
+	rows    int
+}
+
+func newYAMLWriter(w io.Writer, _ formatOptions) rowWriter {
+	return &yamlWriter{w: w}
+}
+
+func (y *yamlWriter) header(columns []*sql.ColumnType) error {
+	y.columns = resultColumns(columns)
+	return nil
+}
+
+func (y *yamlWriter) row(values []any) error {
+	var b strings.Builder
+	for i, cell := range typedCells(y.columns, values) {
+		prefix := "  "
+		if i == 0 {
+			prefix = "- "
+		}
+		b.WriteString(prefix + yamlScalar(y.columns[i].Name) + ": " + yamlValue(cell) + "\n")
+	}
+	if len(y.columns) == 0 {
+		b.WriteString("- {}\n")
+	}
+
+	y.rows++
+	_, err := io.WriteString(y.w, b.String())
+	return err
+}
+
+func (y *yamlWriter) flush() error {
+	if y.rows > 0 {
+		return nil
+	}
+	_, err := io.WriteString(y.w, "[]\n")
+	return err
+}
+
+// yamlValue returns the YAML of a cell, numbers and booleans are written unquoted so they keep their type
+func yamlValue(cell *ResultCell) string {
+	switch value := cell.jsonValue().(type) {
+	case nil:
+		return "null"
+	case json.Number:
+		return value.String()
+	case bool:
+		return fmt.Sprint(value)
+	default:
+		return yamlScalar(cell.Value)
+	}
+}
+
+// yamlScalar returns a string as a YAML scalar, quoting it when it could be read as another type or breaks the syntax
+func yamlScalar(s string) string {
+	switch strings.ToLower(s) {
+	case "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+	default:
+		if yamlPlain.MatchString(s) {
+			return s
+		}
+	}
+
+	// a JSON string is also a double-quoted YAML scalar
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
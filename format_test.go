@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+// writeTestRows writes the rows of a query on an in-memory SQLite database with the writer of a format
+func writeTestRows(t *testing.T, format string, opts formatOptions, d dialect) string {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// every connection to :memory: opens a new database
+	db.SetMaxOpenConns(1)
+
+	for _, statement := range []string{
+		`CREATE TABLE users (id INTEGER, name TEXT, score REAL, note TEXT)`,
+		`INSERT INTO users VALUES (1, 'Ada', 1.5, NULL), (2, 'a,b "c"', -2, 'x|y` + "\n" + `z'), (3, 'tab` + "\t" + `here', NULL, 'it''s\')`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := db.Query("SELECT id, name, score, note FROM users ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	w := formats[format](&b, opts)
+	if dw, ok := w.(dialectWriter); ok {
+		dw.setDialect(d)
+	}
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.header(columns); err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		values, err := scanRow(rows, len(columns))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.row(values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.String()
+}
+
+func TestFormatWriters(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   formatOptions
+		d      dialect
+		want   string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			want: "[\n" +
+				"{\"id\":1,\"name\":\"Ada\",\"score\":1.5,\"note\":null},\n" +
+				"{\"id\":2,\"name\":\"a,b \\\"c\\\"\",\"score\":-2,\"note\":\"x|y\\nz\"},\n" +
+				"{\"id\":3,\"name\":\"tab\\there\",\"score\":null,\"note\":\"it's\\\\\"}\n" +
+				"]",
+		},
+		{
+			name:   "csv",
+			format: "csv",
+			want: "id,name,score,note\r\n" +
+				"1,Ada,1.5,\r\n" +
+				"2,\"a,b \"\"c\"\"\",-2,\"x|y\r\nz\"\r\n" +
+				"3,tab\there,,it's\\\r\n",
+		},
+		{
+			name:   "csv with null text",
+			format: "csv",
+			opts:   formatOptions{null: "NULL"},
+			want: "id,name,score,note\r\n" +
+				"1,Ada,1.5,NULL\r\n" +
+				"2,\"a,b \"\"c\"\"\",-2,\"x|y\r\nz\"\r\n" +
+				"3,tab\there,NULL,it's\\\r\n",
+		},
+		{
+			name:   "csv with delimiters",
+			format: "csv",
+			opts:   formatOptions{delimiter: `\t`, recordDelimiter: `\n`},
+			want: "id\tname\tscore\tnote\n" +
+				"1\tAda\t1.5\t\n" +
+				"2\t\"a,b \"\"c\"\"\"\t-2\t\"x|y\nz\"\n" +
+				"3\t\"tab\there\"\t\tit's\\\n",
+		},
+		{
+			name:   "tsv",
+			format: "tsv",
+			want: "id\tname\tscore\tnote\n" +
+				"1\tAda\t1.5\t\n" +
+				"2\ta,b \"c\"\t-2\tx|y\\nz\n" +
+				"3\ttab\\there\t\tit's\\\\\n",
+		},
+		{
+			name:   "tsv with null text",
+			format: "tsv",
+			opts:   formatOptions{null: `\N`},
+			want: "id\tname\tscore\tnote\n" +
+				"1\tAda\t1.5\t\\N\n" +
+				"2\ta,b \"c\"\t-2\tx|y\\nz\n" +
+				"3\ttab\\there\t\\N\tit's\\\\\n",
+		},
+		{
+			name:   "markdown",
+			format: "markdown",
+			want: "| id | name | score | note |\n" +
+				"| ---: | --- | ---: | --- |\n" +
+				"| 1 | Ada | 1.5 |  |\n" +
+				"| 2 | a,b \"c\" | -2 | x\\|y<br>z |\n" +
+				"| 3 | tab\there |  | it's\\ |\n",
+		},
+		{
+			name:   "inserts",
+			format: "inserts",
+			opts:   formatOptions{table: "app.users"},
+			d:      postgresDialect{},
+			want: "INSERT INTO \"app\".\"users\" (\"id\", \"name\", \"score\", \"note\") VALUES (1, 'Ada', 1.5, NULL);\n" +
+				"INSERT INTO \"app\".\"users\" (\"id\", \"name\", \"score\", \"note\") VALUES (2, 'a,b \"c\"', -2, 'x|y\nz');\n" +
+				"INSERT INTO \"app\".\"users\" (\"id\", \"name\", \"score\", \"note\") VALUES (3, 'tab\there', NULL, 'it''s\\');\n",
+		},
+		{
+			name:   "inserts for mysql",
+			format: "inserts",
+			opts:   formatOptions{table: "users"},
+			d:      mysqlDialect{},
+			want: "INSERT INTO `users` (`id`, `name`, `score`, `note`) VALUES (1, 'Ada', 1.5, NULL);\n" +
+				"INSERT INTO `users` (`id`, `name`, `score`, `note`) VALUES (2, 'a,b \"c\"', -2, 'x|y\nz');\n" +
+				"INSERT INTO `users` (`id`, `name`, `score`, `note`) VALUES (3, 'tab\there', NULL, 'it''s\\\\');\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writeTestRows(t, tt.format, tt.opts, tt.d); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertsWriterNeedsTable(t *testing.T) {
+	w := newInsertsWriter(&strings.Builder{}, formatOptions{})
+	w.(dialectWriter).setDialect(postgresDialect{})
+	if err := w.header(nil); err == nil {
+		t.Fatal("the inserts format wrote a header without a target table")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// readKeywords are the statements a read query may start with
+var readKeywords = map[string]bool{
+	"SELECT":   true,
+	"SHOW":     true,
+	"EXPLAIN":  true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+}
+
+// writeKeywords change data, schema or permissions wherever they appear, e.g. in a writable CTE or EXPLAIN ANALYZE
+var writeKeywords = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"MERGE":    true,
+	"UPSERT":   true,
+	"INTO":     true,
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"TRUNCATE": true,
+	"RENAME":   true,
+	"GRANT":    true,
+	"REVOKE":   true,
+	"CALL":     true,
+	"EXEC":     true,
+	"EXECUTE":  true,
+	"COPY":     true,
+	"LOAD":     true,
+	"LOCK":     true,
+	"SET":      true,
+}
+
+// Query the database after checking the query only reads data, refusing anything but a single SELECT, SHOW or EXPLAIN.
+// Functions with side effects cannot be detected, combine with read-only for a guarantee from the server.
+func (m *Sql) RunReadQuery(
+	ctx context.Context,
+	query string,
+	// Cancel the query on the server after this many seconds, overriding the statement timeout
+	// +optional
+	timeoutSeconds int,
+) (string, error) {
+	if m.Duckdb {
+		if err := checkReadOnly(query, "duckdb"); err != nil {
+			return "", err
+		}
+		return m.duckdbQuery(ctx, query, timeoutSeconds)
+	}
+
+	_, d, _, err := m.connect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := checkReadOnly(query, d.Engine()); err != nil {
+		return "", err
+	}
+
+	return m.query(ctx, query, queryOptions{timeoutSeconds: timeoutSeconds})
+}
+
+// checkReadOnly returns an error unless the query is a single statement that only reads data
+func checkReadOnly(query, engine string) error {
+	statements := splitStatements(query, engine)
+	if len(statements) != 1 {
+		return fmt.Errorf("read queries must contain exactly one statement, found %d", len(statements))
+	}
+
+	words := statementWords(statements[0], engine)
+	if len(words) == 0 || !readKeywords[words[0]] {
+		return fmt.Errorf("read queries must start with SELECT, SHOW, EXPLAIN, DESCRIBE, WITH, VALUES or TABLE")
+	}
+	for _, word := range words {
+		if writeKeywords[word] {
+			return fmt.Errorf("read queries cannot contain %s", word)
+		}
+	}
+
+	return nil
+}
+
+// statementWords returns the upper-cased keywords and unquoted identifiers of a statement, skipping literals, quoted
+// identifiers and comments
+func statementWords(statement, engine string) []string {
+	mysqlSyntax := engine == "mysql"
+
+	var words []string
+	for i := 0; i < len(statement); {
+		if !isWordStart(statement[i]) || (statement[i] == 'E' || statement[i] == 'e') && !mysqlSyntax && strings.HasPrefix(statement[i+1:], "'") {
+			i += tokenLength(statement[i:], mysqlSyntax)
+			continue
+		}
+
+		end := i + 1
+		for end < len(statement) && (isWordStart(statement[end]) || statement[end] >= '0' && statement[end] <= '9' || statement[end] == '$') {
+			end++
+		}
+		words = append(words, strings.ToUpper(statement[i:end]))
+		i = end
+	}
+
+	return words
+}
+
+// isWordStart reports whether c can start a keyword or unquoted identifier
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
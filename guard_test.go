@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  string
+		query   string
+		wantErr bool
+	}{
+		{name: "select", query: "SELECT * FROM users"},
+		{name: "lower case", query: "select 1"},
+		{name: "trailing delimiter", query: "SELECT 1;"},
+		{name: "leading comment", query: "-- report\nSELECT 1"},
+		{name: "with", query: "WITH t AS (SELECT 1) SELECT * FROM t"},
+		{name: "explain", query: "EXPLAIN SELECT 1"},
+		{name: "show", engine: "mysql", query: "SHOW TABLES"},
+		{name: "values", query: "VALUES (1), (2)"},
+		{name: "keyword in literal", query: "SELECT 'DELETE FROM users'"},
+		{name: "keyword in quoted identifier", query: `SELECT 1 AS "update"`},
+		{name: "keyword in comment", query: "SELECT 1 /* then DROP it */"},
+		{name: "empty", query: "", wantErr: true},
+		{name: "only comment", query: "-- nothing", wantErr: true},
+		{name: "insert", query: "INSERT INTO users VALUES (1)", wantErr: true},
+		{name: "second statement", query: "SELECT 1; DROP TABLE users", wantErr: true},
+		{name: "two selects", query: "SELECT 1; SELECT 2", wantErr: true},
+		{name: "writing cte", query: "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d", wantErr: true},
+		{name: "select into", query: "SELECT * INTO backup FROM users", wantErr: true},
+		{name: "locking select", query: "SELECT * FROM users FOR UPDATE", wantErr: true},
+		{name: "explain analyze of a write", query: "EXPLAIN ANALYZE DELETE FROM users", wantErr: true},
+		{name: "mysql hidden statement", engine: "mysql", query: `SELECT '\'; DROP TABLE users; -- '`},
+		{name: "postgres statement after backslash", engine: "postgres", query: `SELECT '\'; DROP TABLE users; -- '`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := tt.engine
+			if engine == "" {
+				engine = "postgres"
+			}
+
+			err := checkReadOnly(tt.query, engine)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkReadOnly(%q) error = %v, want error %v", tt.query, err, tt.wantErr)
+			}
+		})
+	}
+}
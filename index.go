@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// IndexDetails represents an index of a table
+type IndexDetails struct {
+	Name string
+	// Columns are the key columns in index order, expressions for expression indexes
+	Columns []string
+	Unique  bool
+	// Method is the access method of the index, e.g. btree, hash or gin
+	Method string
+}
+
+// indexLister is implemented by dialects that can list the indexes of a table
+type indexLister interface {
+	// IndexesQuery lists the index name, column, uniqueness (YES or NO) and method of every key column of the indexes
+	// of a table, ordered by index and column position
+	IndexesQuery(database, table string) (string, error)
+}
+
+// List the indexes of a table with their columns, uniqueness and method
+func (m *Sql) ListIndexes(ctx context.Context, table string) ([]*IndexDetails, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(indexLister)
+	if !ok {
+		return nil, fmt.Errorf("listing indexes is not supported for %s", d.Name())
+	}
+	query, err := l.IndexesQuery(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := []*IndexDetails{}
+	var index *IndexDetails
+	for rows.Next() {
+		var name, unique, method string
+		var column sql.NullString
+		if err := rows.Scan(&name, &column, &unique, &method); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by index, so a new name starts the next index
+		if index == nil || index.Name != name {
+			index = &IndexDetails{Name: name, Unique: unique == "YES", Method: strings.ToLower(method)}
+			indexes = append(indexes, index)
+		}
+		index.Columns = append(index.Columns, column.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return indexes, nil
+}
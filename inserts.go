@@ -0,0 +1,117 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialectWriter is implemented by writers whose output depends on the dialect of the database
+type dialectWriter interface {
+	setDialect(d dialect)
+}
+
+// insertsWriter writes an INSERT statement per row into the target table, in the dialect of the database
+type insertsWriter struct {
+	w       io.Writer
+	table   string
+	d       dialect
+	columns []*ResultColumn
+	prefix  string
+}
+
+func newInsertsWriter(w io.Writer, opts formatOptions) rowWriter {
+	return &insertsWriter{w: w, table: opts.table}
+}
+
+func (i *insertsWriter) setDialect(d dialect) {
+	i.d = d
+}
+
+func (i *insertsWriter) header(columns []*sql.ColumnType) error {
+	if i.table == "" {
+		return fmt.Errorf("the inserts format needs a target table")
+	}
+
+	i.columns = resultColumns(columns)
+	names := columnNames(columns)
+	for n, name := range names {
+		names[n] = i.d.Quote(name)
+	}
+	i.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES (", quoteQualified(i.d, i.table), strings.Join(names, ", "))
+
+	return nil
+}
+
+func (i *insertsWriter) row(values []any) error {
+	literals := make([]string, len(values))
+	for n, value := range values {
+		literals[n] = sqlLiteral(i.d, value, typedCell(value, i.columns[n].DatabaseType))
+	}
+
+	_, err := io.WriteString(i.w, i.prefix+strings.Join(literals, ", ")+");\n")
+	return err
+}
+
+func (i *insertsWriter) flush() error { return nil }
+
+// sqlLiteral returns a value as a literal of the dialect
+func sqlLiteral(d dialect, value any, cell *ResultCell) string {
+	if cell.Null {
+		return "NULL"
+	}
+
+	switch cell.Type {
+	case "integer", "decimal", "float":
+		// NaN and infinity have no numeric literal
+		if n, err := strconv.ParseFloat(cell.Value, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+			return cell.Value
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(cell.Value); err == nil {
+			return templateLiteral(d, b)
+		}
+	case "time":
+		if t, ok := value.(time.Time); ok {
+			return timeLiteral(d, t)
+		}
+	case "bytes":
+		if b, ok := value.([]byte); ok {
+			return bytesLiteral(d, b)
+		}
+	}
+
+	return templateLiteral(d, cell.Value)
+}
+
+// timeLiteral returns a time as a literal of the dialect
+func timeLiteral(d dialect, t time.Time) string {
+	switch d.Engine() {
+	case "postgres":
+		// keep the offset for timestamptz columns
+		return d.QuoteLiteral(t.Format(time.RFC3339Nano))
+	case "sqlserver", "sqlite":
+		return d.QuoteLiteral(t.UTC().Format("2006-01-02 15:04:05.9999999"))
+	}
+
+	return "TIMESTAMP " + d.QuoteLiteral(t.UTC().Format("2006-01-02 15:04:05.999999"))
+}
+
+// bytesLiteral returns binary data as a literal of the dialect
+func bytesLiteral(d dialect, b []byte) string {
+	switch d.Engine() {
+	case "postgres":
+		return "'\\x" + hex.EncodeToString(b) + "'::bytea"
+	case "sqlserver":
+		return "0x" + hex.EncodeToString(b)
+	case "oracle":
+		return "HEXTORAW('" + hex.EncodeToString(b) + "')"
+	}
+
+	return "X'" + hex.EncodeToString(b) + "'"
+}
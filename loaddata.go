@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// loadDataReader is the prefix of the name the file of LoadData is registered under with the mysql driver
+const loadDataReader = "dagger-sql-load-data"
+
+// Bulk load a file into a table with mysql LOAD DATA LOCAL INFILE, which requires local_infile to be enabled on the server
+func (m *Sql) LoadData(
+	ctx context.Context,
+	// Table to load, optionally qualified with its database, e.g. app.users
+	table string,
+	file *dagger.File,
+	// Format of the file, csv (with a header row) or text (tab-separated without a header, the mysql default)
+	// +default="csv"
+	format string,
+	// Columns the fields of the file map to, in order, all columns of the table by default
+	// +optional
+	columns []string,
+) (*ExecResult, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if d.Engine() != "mysql" {
+		return nil, fmt.Errorf("load data is not supported for %s, use copy-in for postgres", d.Name())
+	}
+
+	var options string
+	switch strings.ToLower(format) {
+	case "csv":
+		options = `FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' LINES TERMINATED BY '\n' IGNORE 1 LINES`
+	case "text":
+	default:
+		return nil, fmt.Errorf("unsupported load data format %q, supported formats are csv and text", format)
+	}
+
+	// the handlers of the driver are global, so the name, which the file is also exported as, is unique to the call as
+	// concurrent loads would otherwise stream each other's files
+	reader := fmt.Sprintf("%s-%016x", loadDataReader, rand.Uint64())
+	statement := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s %s", reader, quoteQualified(d, table), options)
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = d.Quote(column)
+		}
+		statement += " (" + strings.Join(quoted, ", ") + ")"
+	}
+	if err := m.checkPolicy(d.Engine(), statement); err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Export(ctx, reader); err != nil {
+		return nil, fmt.Errorf("error exporting file: %w", err)
+	}
+	defer os.Remove(reader)
+	f, err := os.Open(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	// the driver streams the registered reader when the server requests the file
+	mysql.RegisterReaderHandler(reader, func() io.Reader { return f })
+	defer mysql.DeregisterReaderHandler(reader)
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	conn, release, err := serverConn(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := conn.ExecContext(ctx, statement)
+	if err != nil {
+		return nil, fmt.Errorf("error loading data into %s: %w", table, err)
+	}
+	total := &ExecResult{}
+	total.add(result)
+
+	// rows that do not fit the table are skipped with a warning instead of failing the load
+	if total.Notices, err = serverWarnings(ctx, conn, d); err != nil {
+		return nil, err
+	}
+
+	return total, nil
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"dagger/sql/internal/dagger"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"net/url"
 	"strings"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"golang.org/x/crypto/ssh"
 )
 
 // ColumnDetails represents the details of a column in a database table
@@ -17,85 +18,441 @@ type ColumnDetails struct {
 	Name       string
 	DataType   string
 	IsNullable bool
+	// DefaultValue is the default expression of the column, empty when it has none
+	DefaultValue string
+	// IsIdentity is true for identity and auto increment columns, e.g. serial, IDENTITY or AUTO_INCREMENT
+	IsIdentity bool
+	// CharMaxLength is the maximum length of a character column, 0 for other columns
+	CharMaxLength int
+	// NumericPrecision and NumericScale are those of a numeric column, 0 for other columns
+	NumericPrecision int
+	NumericScale     int
+	// Comment is the description of the column, empty when it has none
+	Comment string
+	// Position is the 1-based position of the column in its table
+	Position int
 }
 
-type Sql struct {
+// DatabaseInfo represents the engine, version and flavor of a database server
+type DatabaseInfo struct {
+	Engine  string
+	Version string
+	Flavor  string
+}
+
+// NamedConnection represents a connection string registered under a name
+type NamedConnection struct {
+	Name string
 	Conn *dagger.Secret // +private
 }
 
-func New(conn *dagger.Secret) *Sql { return &Sql{Conn: conn} }
+type Sql struct {
+	Conn   *dagger.Secret // +private
+	Sqlite *dagger.File   // +private
+
+	Connections []*NamedConnection // +private
 
-func (m *Sql) connect() (*sql.DB, string, string, error) {
-	c, err := m.Conn.Plaintext(context.Background())
-	if err != nil {
-		return nil, "", "", fmt.Errorf("error getting plaintext connection: %w", err)
+	Driver   string         // +private
+	Host     string         // +private
+	Port     int            // +private
+	User     string         // +private
+	Password *dagger.Secret // +private
+	Database string         // +private
+
+	Service *dagger.Service // +private
+	Socket  *dagger.Socket  // +private
+
+	TLSCA   *dagger.Secret // +private
+	TLSCert *dagger.Secret // +private
+	TLSKey  *dagger.Secret // +private
+	TLSMode string         // +private
+
+	SSHHost    string         // +private
+	SSHUser    string         // +private
+	SSHKey     *dagger.Secret // +private
+	SSHHostKey string         // +private
+
+	PoolMaxOpen     int // +private
+	PoolMaxIdle     int // +private
+	PoolMaxLifetime int // +private
+
+	ReadOnlySession bool // +private
+
+	SimpleProtocol bool // +private
+
+	AWSRegion      string         // +private
+	AWSCredentials *dagger.Secret // +private
+
+	CloudSQLInstance string         // +private
+	CloudSQLKey      *dagger.Secret // +private
+
+	AzureTenant       string         // +private
+	AzureClientID     string         // +private
+	AzureClientSecret *dagger.Secret // +private
+
+	VaultAddr  string         // +private
+	VaultToken *dagger.Secret // +private
+	VaultLease string         // +private
+
+	ConnectTimeout   int // +private
+	StatementTimeout int // +private
+
+	RetryAttempts int // +private
+	RetryBackoff  int // +private
+
+	SessionSearchPath string // +private
+	SessionTimezone   string // +private
+	SessionRole       string // +private
+
+	BigqueryCredentials *dagger.Secret // +private
+
+	PreparedStatements []*PreparedStatement // +private
+
+	PolicyAllow []string // +private
+	PolicyDeny  []string // +private
+
+	CacheTTL    int    // +private
+	CacheVolume string // +private
+
+	Duckdb     bool              // +private
+	DuckdbFile *dagger.File      // +private
+	DuckdbData *dagger.Directory // +private
+
+	// the open connection, Dagger does not serialize unexported fields so it is only reused within a single function call
+	db         *sql.DB
+	dbDialect  dialect
+	dbDatabase string
+	// the client of the SSH tunnel the connection is dialed through
+	sshClient *ssh.Client
+	// notices the server sent on any connection, see ExecResult.Notices
+	notices *noticeBuffer
+}
+
+func New(
+	// +optional
+	conn *dagger.Secret,
+) *Sql {
+	return &Sql{Conn: conn}
+}
+
+// Register a connection string under a name, e.g. primary, replica or analytics
+func (m *Sql) WithNamedConnection(name string, conn *dagger.Secret) *Sql {
+	for _, c := range m.Connections {
+		if c.Name == name {
+			c.Conn = conn
+			return m
+		}
 	}
 
-	var (
-		db       *sql.DB
-		dbType   string
-		database string
-	)
-	conn := strings.ToLower(c)
-	switch {
-	case strings.HasPrefix(conn, "postgres://"), strings.HasPrefix(conn, "postgresql://"), strings.Contains(conn, "user=") && strings.Contains(conn, "dbname="):
-		d, err := sql.Open("pgx", c)
-		if err != nil {
-			return nil, "", "", fmt.Errorf("error opening database connection: %w", err)
+	m.Connections = append(m.Connections, &NamedConnection{Name: name, Conn: conn})
+	return m
+}
+
+// Use the connection registered under a name for the following calls
+func (m *Sql) On(name string) (*Sql, error) {
+	for _, c := range m.Connections {
+		if c.Name == name {
+			m.Conn = c.Conn
+			return m, nil
 		}
-		db = d
-		dbType = "postgres"
-		u, err := url.Parse(c)
+	}
+
+	return nil, fmt.Errorf("no connection named %s, use with-named-connection first", name)
+}
+
+// Use a service account key in JSON format to authenticate bigquery:// connections
+func (m *Sql) WithBigqueryCredentials(credentials *dagger.Secret) *Sql {
+	m.BigqueryCredentials = credentials
+	return m
+}
+
+// Tune the connection pool, a value of 0 keeps the driver default
+func (m *Sql) WithPool(
+	// Maximum number of open connections
+	// +optional
+	maxOpen int,
+	// Maximum number of idle connections
+	// +optional
+	maxIdle int,
+	// Maximum lifetime of a connection in seconds
+	// +optional
+	maxLifetimeSeconds int,
+) *Sql {
+	m.PoolMaxOpen = maxOpen
+	m.PoolMaxIdle = maxIdle
+	m.PoolMaxLifetime = maxLifetimeSeconds
+	return m
+}
+
+// Apply timeouts in seconds to connecting and to every statement, a value of 0 disables the timeout
+func (m *Sql) WithTimeouts(
+	// +optional
+	connectSeconds int,
+	// +optional
+	statementSeconds int,
+) *Sql {
+	m.ConnectTimeout = connectSeconds
+	m.StatementTimeout = statementSeconds
+	return m
+}
+
+// statementContext returns a context with the statement timeout applied
+func (m *Sql) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.StatementTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(m.StatementTimeout)*time.Second)
+}
+
+// queryContext applies the timeout of a single call, falling back to the statement timeout
+func (m *Sql) queryContext(ctx context.Context, timeoutSeconds int) (context.Context, context.CancelFunc) {
+	if timeoutSeconds <= 0 {
+		return m.statementContext(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+}
+
+// Close the open database connection
+func (m *Sql) Close() error {
+	var err error
+	if m.db != nil {
+		err = m.db.Close()
+		m.db = nil
+	}
+
+	// the tunnel is closed after the pool, whose connections run through it
+	return errors.Join(err, m.closeSSH())
+}
+
+// connect returns the open database connection, opening it on first use
+func (m *Sql) connect(ctx context.Context) (*sql.DB, dialect, string, error) {
+	if m.db == nil {
+		var db *sql.DB
+		var d dialect
+		var database string
+		err := m.retry(ctx, nil, func() error {
+			var err error
+			db, d, database, err = m.open(ctx)
+			return err
+		})
 		if err != nil {
-			return nil, "", "", fmt.Errorf("error parsing connection string: %w", err)
+			m.closeSSH()
+			return nil, nil, "", err
 		}
+		m.db, m.dbDialect, m.dbDatabase = db, d, database
+	}
+
+	return m.db, m.dbDialect, m.dbDatabase, nil
+}
 
-		database = strings.TrimPrefix(u.Path, "/")
-	case strings.HasPrefix(conn, "mysql://"), strings.Contains(conn, "@tcp("), strings.Contains(conn, "user:") && strings.Contains(conn, "@/"):
-		d, err := sql.Open("mysql", c)
+// open opens a new database connection and returns it with its dialect and database name
+func (m *Sql) open(ctx context.Context) (*sql.DB, dialect, string, error) {
+	if m.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(m.ConnectTimeout)*time.Second)
+		defer cancel()
+	}
+
+	if m.Sqlite != nil {
+		db, database, err := sqliteDialect{}.Open(ctx, m, "")
 		if err != nil {
-			return nil, "", "", fmt.Errorf("error opening database connection: %w", err)
-		}
-		db = d
-		dbType = "mysql"
-		parts := strings.SplitN(conn, "/", 2)
-		if len(parts) < 2 {
-			return nil, "", "", fmt.Errorf("unable to determine database name from connection string: %s", c)
+			return nil, nil, "", err
 		}
 
-		pathParts := strings.SplitN(parts[1], "?", 2)
-		dbName := pathParts[0]
-		if dbName == "" {
-			return nil, "", "", fmt.Errorf("invalid DSN: missing database name")
+		return db, sqliteDialect{}, database, nil
+	}
+
+	c, err := m.dsn(ctx)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	d, err := detectDialect(c)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if m.hasSession() && d.Engine() != "postgres" && d.Engine() != "mysql" {
+		return nil, nil, "", fmt.Errorf("session options are not supported for %s", d.Name())
+	}
+	if (m.TLSMode != "" || m.TLSCA != nil || m.TLSCert != nil || m.TLSKey != nil) && d.Engine() != "postgres" && d.Engine() != "mysql" {
+		return nil, nil, "", fmt.Errorf("TLS options are not supported for %s", d.Name())
+	}
+	if m.tokenAuth() && d.Engine() != "postgres" && d.Engine() != "mysql" {
+		return nil, nil, "", fmt.Errorf("token authentication is not supported for %s", d.Name())
+	}
+	if m.CloudSQLInstance != "" && d.Engine() != "postgres" && d.Engine() != "mysql" {
+		return nil, nil, "", fmt.Errorf("the Cloud SQL connector is not supported for %s", d.Name())
+	}
+	if m.SSHHost != "" && d.Engine() != "postgres" && d.Engine() != "mysql" {
+		return nil, nil, "", fmt.Errorf("SSH tunnels are not supported for %s", d.Name())
+	}
+	if m.SimpleProtocol && d.Engine() != "postgres" {
+		return nil, nil, "", fmt.Errorf("the simple protocol is not supported for %s", d.Name())
+	}
+	if m.ReadOnlySession {
+		switch d.Engine() {
+		case "postgres", "mysql", "sqlite":
+		default:
+			return nil, nil, "", fmt.Errorf("read-only mode is not supported for %s", d.Name())
 		}
-		database = dbName
-	default:
-		return nil, "", "", fmt.Errorf("unable to determine database type from connection string: %s", c)
+	}
+
+	db, database, err := d.Open(ctx, m, c)
+	if err != nil {
+		return nil, nil, "", err
 	}
 	if database == "" {
-		return nil, "", "", fmt.Errorf("unable to determine database name from connection string: %s", c)
+		db.Close()
+		return nil, nil, "", fmt.Errorf("unable to determine database name from connection string: %s", c)
+	}
+
+	if f, ok := d.(flavorDetector); ok {
+		if d, err = f.DetectFlavor(ctx, db, strings.ToLower(c)); err != nil {
+			db.Close()
+			return nil, nil, "", err
+		}
+	}
+
+	// establish the first connection within the connect timeout
+	if m.ConnectTimeout > 0 {
+		if err := db.PingContext(ctx); err != nil {
+			db.Close()
+			return nil, nil, "", fmt.Errorf("error connecting to database: %w", err)
+		}
+	}
+
+	m.configurePool(db)
+
+	return db, d, database, nil
+}
+
+// configurePool applies the pool options to an open database
+func (m *Sql) configurePool(db *sql.DB) {
+	if m.PoolMaxOpen > 0 {
+		db.SetMaxOpenConns(m.PoolMaxOpen)
+	}
+	if m.PoolMaxIdle > 0 {
+		db.SetMaxIdleConns(m.PoolMaxIdle)
+	}
+	if m.PoolMaxLifetime > 0 {
+		db.SetConnMaxLifetime(time.Duration(m.PoolMaxLifetime) * time.Second)
+	}
+}
+
+// Return the engine, version and flavor of the database, e.g. mysql and mariadb
+func (m *Sql) DatabaseInfo(ctx context.Context) (*DatabaseInfo, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	version, err := serverVersion(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatabaseInfo{
+		Engine:  d.Engine(),
+		Version: version,
+		Flavor:  d.Name(),
+	}, nil
+}
+
+// Verify the database accepts connections, retrying on failure, and return the server version
+func (m *Sql) Ping(
+	ctx context.Context,
+	// Number of retries after the first attempt fails
+	// +default=3
+	retries int,
+	// Seconds to wait between attempts
+	// +default=1
+	delaySeconds int,
+) (string, error) {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Duration(delaySeconds) * time.Second):
+			}
+		}
+
+		var version string
+		if version, err = m.ping(ctx); err == nil {
+			return version, nil
+		}
+	}
+
+	return "", fmt.Errorf("error pinging database after %d attempts: %w", retries+1, err)
+}
+
+// Wait until the database accepts connections, backing off exponentially between attempts
+func (m *Sql) WaitFor(
+	ctx context.Context,
+	// Seconds to wait before giving up
+	// +default=60
+	timeoutSeconds int,
+) (*Sql, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	delay := 250 * time.Millisecond
+	for {
+		_, err := m.ping(ctx)
+		if err == nil {
+			return m, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("database not ready after %d seconds: %w", timeoutSeconds, err)
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, 5*time.Second)
+	}
+}
+
+// ping opens a connection, pings the database and returns the server version
+func (m *Sql) ping(ctx context.Context) (string, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return "", fmt.Errorf("error pinging database: %w", err)
 	}
 
-	return db, dbType, database, nil
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	return serverVersion(ctx, db, d)
 }
 
 // List the tables in a database and return the names of the tables
 func (m *Sql) ListTables(
+	ctx context.Context,
 	// +default="public"
 	schema string,
+	// Catalog to list the tables from, defaults to the catalog of the connection (Trino only)
+	// +optional
+	catalog string,
 ) ([]string, error) {
-	db, dbType, database, err := m.connect()
+	db, d, database, err := m.connect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database connection: %w", err)
 	}
-	defer db.Close()
+	query := d.TablesQuery(database, schema, catalog)
 
-	query := fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = '%s' AND table_catalog = '%s'", schema, database)
-	if dbType == "mysql" {
-		query = fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = '%s'", database)
-	}
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying tables: %w", err)
 	}
@@ -117,19 +474,17 @@ func (m *Sql) ListTables(
 }
 
 // List the columns in a table and and return the names
-func (m *Sql) ListColumns(table string) ([]string, error) {
-	db, dbType, database, err := m.connect()
+func (m *Sql) ListColumns(ctx context.Context, table string) ([]string, error) {
+	db, d, database, err := m.connect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database connection: %w", err)
 	}
-	defer db.Close()
+	query := d.ColumnsQuery(database, table)
 
-	query := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = '%s' AND table_catalog = '%s'", table, database)
-	if dbType == "mysql" {
-		query = fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = '%s'", table)
-	}
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying columns: %w", err)
 	}
@@ -152,83 +507,439 @@ func (m *Sql) ListColumns(table string) ([]string, error) {
 }
 
 // List the details for a specific column in a table
-func (m *Sql) ListColumnDetails(table, column string) (*ColumnDetails, error) {
-	db, dbType, database, err := m.connect()
+func (m *Sql) ListColumnDetails(ctx context.Context, table, column string) (*ColumnDetails, error) {
+	db, d, database, err := m.connect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database connection: %w", err)
 	}
-	defer db.Close()
+	query := d.ColumnDetailsQuery(database, table)
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
 
-	query := fmt.Sprintf("SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = '%s' AND table_catalog = '%s' AND column_name = '%s'", table, database, column)
-	if dbType == "mysql" {
-		query = fmt.Sprintf("SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = '%s' AND column_name = '%s'", table, column)
+	columns, err := queryColumnDetails(ctx, db, query)
+	if err != nil {
+		return nil, err
 	}
 
+	// engines such as oracle and snowflake store unquoted names in upper case
 	details := &ColumnDetails{}
-	rows, err := db.Query(query)
+	for _, c := range columns {
+		if c.Name == column {
+			return c, nil
+		}
+		if details.Name == "" && strings.EqualFold(c.Name, column) {
+			details = c
+		}
+	}
+
+	return details, nil
+}
+
+// Describe every column of a table with its type, nullability, default, identity, length, precision, comment and position in a single query
+func (m *Sql) DescribeColumns(ctx context.Context, table string) ([]*ColumnDetails, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	query := d.ColumnDetailsQuery(database, table)
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	columns, err := queryColumnDetails(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+
+	return columns, nil
+}
+
+// queryColumnDetails runs the ColumnDetailsQuery of a dialect and scans the columns it lists
+func queryColumnDetails(ctx context.Context, db *sql.DB, query string) ([]*ColumnDetails, error) {
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying columns: %w", err)
 	}
 	defer rows.Close()
 
+	columns := []*ColumnDetails{}
 	for rows.Next() {
-		var isNullable string
-		if err := rows.Scan(&details.Name, &details.DataType, &isNullable); err != nil {
+		details := &ColumnDetails{}
+		var isNullable, isIdentity string
+		var defaultValue, comment sql.NullString
+		var charMaxLength, numericPrecision, numericScale sql.NullInt64
+		if err := rows.Scan(&details.Name, &details.DataType, &isNullable, &defaultValue, &charMaxLength, &numericPrecision, &numericScale, &isIdentity, &comment, &details.Position); err != nil {
 			return nil, fmt.Errorf("error scanning row: %w", err)
 		}
 		details.IsNullable = isNullable == "YES"
-		break // We only need the first row
+		details.IsIdentity = isIdentity == "YES"
+		details.DefaultValue = defaultValue.String
+		details.CharMaxLength = int(charMaxLength.Int64)
+		details.NumericPrecision = int(numericPrecision.Int64)
+		details.NumericScale = int(numericScale.Int64)
+		details.Comment = comment.String
+		columns = append(columns, details)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return details, nil
+	return columns, nil
 }
 
 // Query the database and return the results in comma-separated format
-func (m *Sql) RunQuery(query string) (string, error) {
-	db, _, _, err := m.connect()
+func (m *Sql) RunQuery(
+	ctx context.Context,
+	query string,
+	// Cancel the query on the server after this many seconds, overriding the statement timeout
+	// +optional
+	timeoutSeconds int,
+	// Maximum number of rows to return
+	// +optional
+	limit int,
+	// Number of rows to skip
+	// +optional
+	offset int,
+	// Show the plan of the query instead of running it
+	// +optional
+	dryRun bool,
+	// Output format, e.g. csv (with a header row), json, yaml, table, vertical or inserts, the rows are joined with commas
+	// by default
+	// +optional
+	format string,
+	// Target table of the inserts format
+	// +optional
+	table string,
+	// Text of NULL values in formats that write values as text, e.g. NULL or \N, json, yaml and inserts keep their own
+	// null and it is empty by default
+	// +optional
+	null string,
+	// Columns to return in this order, every column of the query by default
+	// +optional
+	columns []string,
+	// Columns to leave out of the results
+	// +optional
+	exclude []string,
+	// Field delimiter of the csv format instead of a comma, e.g. | or \x01
+	// +optional
+	delimiter string,
+	// Record delimiter of the csv format instead of a line break, e.g. \n or \x02
+	// +optional
+	recordDelimiter string,
+) (string, error) {
+	if dryRun {
+		return m.dryRun(ctx, []string{query})
+	}
+
+	opts := queryOptions{
+		timeoutSeconds: timeoutSeconds,
+		limit:          limit,
+		offset:         offset,
+		columns:        columns,
+		exclude:        exclude,
+		output: formatOptions{
+			table:           table,
+			null:            null,
+			delimiter:       delimiter,
+			recordDelimiter: recordDelimiter,
+		},
+	}
+	run := func() (string, error) {
+		if format != "" {
+			return m.formatted(ctx, query, format, opts)
+		}
+		if m.Duckdb && limit == 0 && offset == 0 && len(columns) == 0 && len(exclude) == 0 {
+			return m.duckdbQuery(ctx, query, timeoutSeconds)
+		}
+		return m.query(ctx, query, opts)
+	}
+	if m.CacheTTL > 0 {
+		return m.cachedQuery(ctx, query, fmt.Sprintf("limit=%d offset=%d format=%s table=%s null=%s columns=%q exclude=%q delimiter=%q recordDelimiter=%q", limit, offset, format, table, null, columns, exclude, delimiter, recordDelimiter), run)
+	}
+
+	return run()
+}
+
+// QueryPage represents a page of query results
+type QueryPage struct {
+	// Rows in comma-separated format
+	Rows string
+	// Truncated is true when the query returned more rows than the page holds
+	Truncated bool
+}
+
+// Query the database and return a page of the results, reporting whether more rows follow
+func (m *Sql) RunQueryPage(
+	ctx context.Context,
+	query string,
+	// Maximum number of rows to return
+	// +default=100
+	limit int,
+	// Number of rows to skip
+	// +optional
+	offset int,
+) (*QueryPage, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	result, err := m.fetch(ctx, query, queryOptions{limit: limit, offset: offset})
 	if err != nil {
-		return "", fmt.Errorf("error opening database connection: %w", err)
+		return nil, err
 	}
-	defer db.Close()
 
-	rows, err := db.Query(query)
+	return &QueryPage{Rows: result.csv(""), Truncated: result.truncated}, nil
+}
+
+// Query the database with bind parameters, using the placeholders of the database (e.g. $1, ? or @p1), and return the results in comma-separated format
+func (m *Sql) RunQueryArgs(
+	ctx context.Context,
+	query string,
+	// Values bound to the placeholders in order
+	// +optional
+	args []string,
+) (string, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+
+	return m.query(ctx, query, queryOptions{}, values...)
+}
+
+// Query the database with typed bind parameters from a JSON array, e.g. [42, "name", true, null], and return the results in comma-separated format
+func (m *Sql) RunQueryJSONArgs(
+	ctx context.Context,
+	query string,
+	// JSON array of values bound to the placeholders in order
+	args string,
+) (string, error) {
+	values, err := jsonArgs(args)
 	if err != nil {
-		return "", fmt.Errorf("error querying database: %w", err)
+		return "", err
 	}
+
+	return m.query(ctx, query, queryOptions{}, values...)
+}
+
+// jsonArgs decodes a JSON array into bind parameters, keeping integers exact
+func jsonArgs(args string) ([]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(args))
+	decoder.UseNumber()
+
+	var values []any
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("error parsing JSON arguments: %w", err)
+	}
+
+	for i, value := range values {
+		switch v := value.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				values[i] = f
+			}
+		case map[string]any, []any:
+			// pass nested values as JSON text, e.g. for json and jsonb columns
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding argument %d: %w", i+1, err)
+			}
+			values[i] = string(b)
+		}
+	}
+
+	return values, nil
+}
+
+// queryOptions controls how a query is run and which of its rows are read
+type queryOptions struct {
+	timeoutSeconds int
+	// internal queries are generated by the module, e.g. to explain a statement, and skip the policy
+	internal bool
+	// limit is the maximum number of rows to read, 0 reads every row
+	limit  int
+	offset int
+	// batchSize fetches postgres rows through a server-side cursor in batches of this size, 0 reads them from the query
+	batchSize int
+	// columns are the columns to write in order, all of them by default, without the exclude columns
+	columns []string
+	exclude []string
+	// output configures the writer of the output format
+	output formatOptions
+}
+
+// queryResult holds the rows read from a query
+type queryResult struct {
+	columns []string
+	rows    [][]any
+	// truncated reports whether rows beyond the limit were left unread
+	truncated bool
+}
+
+// query runs a query with optional bind parameters and returns the results in comma-separated format
+func (m *Sql) query(ctx context.Context, query string, opts queryOptions, args ...any) (string, error) {
+	result, err := m.fetch(ctx, query, opts, args...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.rows) == 0 {
+		return "", fmt.Errorf("no results found")
+	}
+
+	return result.csv(opts.output.null), nil
+}
+
+// fetch runs a query and reads its rows into memory
+func (m *Sql) fetch(ctx context.Context, query string, opts queryOptions, args ...any) (*queryResult, error) {
+	result := &queryResult{}
+	truncated, err := m.stream(ctx, query, opts, result, args...)
+	if err != nil {
+		return nil, err
+	}
+	result.truncated = truncated
+
+	return result, nil
+}
+
+// stream runs a query and passes its rows to w as they are read, skipping offset rows and stopping at the limit on the open cursor
+func (m *Sql) stream(ctx context.Context, query string, opts queryOptions, w rowWriter, args ...any) (bool, error) {
+	if m.Duckdb {
+		return false, fmt.Errorf("bind parameters, limits, offsets, columns and output formats are not supported for duckdb")
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if !opts.internal {
+		if err := m.checkPolicy(d.Engine(), query); err != nil {
+			return false, err
+		}
+	}
+	if len(opts.columns) > 0 || len(opts.exclude) > 0 {
+		w = &projectionWriter{w: w, columns: opts.columns, exclude: opts.exclude}
+	}
+	if dw, ok := w.(dialectWriter); ok {
+		dw.setDialect(d)
+	}
+	ctx, cancel := m.queryContext(ctx, opts.timeoutSeconds)
+	defer cancel()
+
+	if opts.batchSize > 0 && d.Engine() == "postgres" {
+		if opts.limit > 0 || opts.offset > 0 {
+			return false, fmt.Errorf("limits and offsets cannot be combined with a cursor")
+		}
+		return false, streamCursor(ctx, db, d, query, opts.batchSize, w, args...)
+	}
+
+	// only the query is retried, rows that were already written cannot be taken back, and only when it did not take
+	// effect as it may write data, e.g. INSERT ... RETURNING
+	var rows *sql.Rows
+	var release func()
+	err = m.retryStatement(ctx, d, func() error {
+		var conn *sql.Conn
+		var err error
+		if conn, release, err = serverConn(ctx, db, d); err != nil {
+			return err
+		}
+		if rows, err = conn.QueryContext(ctx, query, args...); err != nil {
+			release()
+			return fmt.Errorf("error querying database: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	defer release()
 	defer rows.Close()
 
-	columns, err := rows.Columns()
+	columns, err := rows.ColumnTypes()
 	if err != nil {
-		return "", fmt.Errorf("error getting columns: %w", err)
+		return false, fmt.Errorf("error getting columns: %w", err)
+	}
+	if err := w.header(columns); err != nil {
+		return false, fmt.Errorf("error writing header: %w", err)
 	}
 
-	var results []string
-	for rows.Next() {
-		values := make([]any, len(columns))
-		valuePtrs := make([]any, len(columns))
-		for i := range columns {
-			valuePtrs[i] = &values[i]
+	truncated := false
+	for read, skipped := 0, 0; rows.Next(); skipped++ {
+		if skipped < opts.offset {
+			continue
 		}
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return "", fmt.Errorf("error scanning row: %w", err)
+		if opts.limit > 0 && read == opts.limit {
+			truncated = true
+			break
 		}
-		var row []string
-		for _, value := range values {
-			row = append(row, fmt.Sprintf("%v", value))
+
+		values, err := scanRow(rows, len(columns))
+		if err != nil {
+			return false, err
+		}
+		if err := w.row(values); err != nil {
+			return false, fmt.Errorf("error writing row: %w", err)
 		}
-		results = append(results, strings.Join(row, ","))
+		read++
 	}
 
 	if err := rows.Err(); err != nil {
-		return "", fmt.Errorf("error iterating rows: %w", err)
+		return false, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	if len(results) == 0 {
-		return "", fmt.Errorf("no results found")
+	if err := w.flush(); err != nil {
+		return false, fmt.Errorf("error writing results: %w", err)
+	}
+
+	return truncated, nil
+}
+
+// scanRow scans the current row into a value per column
+func scanRow(rows *sql.Rows, columns int) ([]any, error) {
+	values := make([]any, columns)
+	valuePtrs := make([]any, columns)
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, fmt.Errorf("error scanning row: %w", err)
+	}
+
+	return values, nil
+}
+
+func (r *queryResult) header(columns []*sql.ColumnType) error {
+	r.columns = columnNames(columns)
+	return nil
+}
+
+func (r *queryResult) row(values []any) error {
+	r.rows = append(r.rows, values)
+	return nil
+}
+
+func (r *queryResult) flush() error { return nil }
+
+// csv joins the values of every row with commas, writing NULL as the null text
+func (r *queryResult) csv(null string) string {
+	lines := make([]string, len(r.rows))
+	for i, values := range r.rows {
+		row := make([]string, len(values))
+		for j, value := range values {
+			if value == nil {
+				row[j] = null
+				continue
+			}
+			row[j] = fmt.Sprintf("%v", value)
+		}
+		lines[i] = strings.Join(row, ",")
 	}
 
-	return strings.Join(results, "\n"), nil
+	return strings.Join(lines, "\n")
 }
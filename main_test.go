@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// rowsDriver is a driver that answers every query with the same rows, types are the database types of the columns
+type rowsDriver struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (d *rowsDriver) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *rowsDriver) Driver() driver.Driver                        { return d }
+func (d *rowsDriver) Open(string) (driver.Conn, error)             { return d, nil }
+func (d *rowsDriver) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (d *rowsDriver) Close() error                                 { return nil }
+func (d *rowsDriver) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (d *rowsDriver) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{columns: d.columns, types: d.types, rows: d.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.types) {
+		return r.types[i]
+	}
+	return ""
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestListColumnDetails(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// every connection to :memory: opens a new database
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT NOT NULL DEFAULT 'new', total DECIMAL(10, 2))`); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Sql{db: db, dbDialect: sqliteDialect{}, dbDatabase: "main"}
+	tests := []struct {
+		column string
+		want   ColumnDetails
+	}{
+		{column: "id", want: ColumnDetails{Name: "id", DataType: "INTEGER", IsNullable: true, IsIdentity: true, Position: 1}},
+		{column: "status", want: ColumnDetails{Name: "status", DataType: "TEXT", DefaultValue: "'new'", Position: 2}},
+		{column: "TOTAL", want: ColumnDetails{Name: "total", DataType: "DECIMAL(10, 2)", IsNullable: true, Position: 3}},
+		{column: "missing", want: ColumnDetails{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			got, err := m.ListColumnDetails(context.Background(), "orders", tt.column)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("ListColumnDetails(%q) = %+v, want %+v", tt.column, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListColumnDetailsPrecision(t *testing.T) {
+	db := sql.OpenDB(&rowsDriver{
+		columns: []string{"column_name", "data_type", "is_nullable", "column_default", "character_maximum_length", "numeric_precision", "numeric_scale", "is_identity", "comment", "ordinal_position"},
+		rows: [][]driver.Value{
+			{"id", "bigint", "NO", "nextval('orders_id_seq'::regclass)", nil, int64(64), int64(0), "YES", nil, int64(1)},
+			{"total", "numeric", "YES", "0.00", nil, int64(10), int64(2), "NO", "order total", int64(2)},
+			{"status", "character varying", "NO", "'new'::character varying", int64(20), nil, nil, "NO", nil, int64(3)},
+		},
+	})
+	defer db.Close()
+
+	m := &Sql{db: db, dbDialect: postgresDialect{}, dbDatabase: "app"}
+	got, err := m.ListColumnDetails(context.Background(), "orders", "total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ColumnDetails{Name: "total", DataType: "numeric", IsNullable: true, DefaultValue: "0.00", NumericPrecision: 10, NumericScale: 2, Comment: "order total", Position: 2}
+	if *got != want {
+		t.Errorf("ListColumnDetails() = %+v, want %+v", *got, want)
+	}
+}
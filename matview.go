@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaterializedView represents a postgres materialized view
+type MaterializedView struct {
+	Schema string
+	Name   string
+	// Populated is false until the view is refreshed when it was created WITH NO DATA
+	Populated  bool
+	Definition string
+}
+
+// List the materialized views outside the system schemas and whether they are populated (postgres only)
+func (m *Sql) ListMaterializedViews(ctx context.Context) ([]*MaterializedView, error) {
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := materializedViewsSupported(d); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT schemaname, matviewname, ispopulated, definition FROM pg_matviews WHERE schemaname NOT IN ('pg_catalog', 'information_schema') ORDER BY schemaname, matviewname")
+	if err != nil {
+		return nil, fmt.Errorf("error querying materialized views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []*MaterializedView{}
+	for rows.Next() {
+		view := &MaterializedView{}
+		if err := rows.Scan(&view.Schema, &view.Name, &view.Populated, &view.Definition); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return views, nil
+}
+
+// Refresh a materialized view, concurrently keeps it readable while it refreshes but needs a unique index (postgres only)
+func (m *Sql) RefreshMaterializedView(
+	ctx context.Context,
+	// Materialized view to refresh, optionally qualified with its schema, e.g. reporting.daily_totals
+	name string,
+	// +optional
+	concurrently bool,
+	// Cancel the refresh on the server after this many seconds, overriding the statement timeout
+	// +optional
+	timeoutSeconds int,
+) (*ExecResult, error) {
+	_, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := materializedViewsSupported(d); err != nil {
+		return nil, err
+	}
+
+	statement := "REFRESH MATERIALIZED VIEW "
+	if concurrently {
+		statement += "CONCURRENTLY "
+	}
+	statement += quoteQualified(d, name)
+
+	return m.Exec(ctx, statement, timeoutSeconds, false)
+}
+
+// materializedViewsSupported returns an error for databases without pg_matviews, redshift keeps its own catalog
+func materializedViewsSupported(d dialect) error {
+	if d.Engine() != "postgres" || d.Name() == "redshift" {
+		return fmt.Errorf("materialized views are not supported for %s", d.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string   { return "mysql" }
+func (mysqlDialect) Engine() string { return "mysql" }
+
+func (mysqlDialect) Detect(conn string) bool {
+	return strings.HasPrefix(conn, "mysql://") || strings.Contains(conn, "@tcp(") || strings.Contains(conn, "@unix(") || strings.Contains(conn, "user:") && strings.Contains(conn, "@/")
+}
+
+func (d mysqlDialect) Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error) {
+	// parse the DSN with the driver, passwords may contain slashes
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error parsing connection string: %w", err)
+	}
+	if cfg.DBName == "" {
+		return nil, "", fmt.Errorf("invalid DSN: missing database name")
+	}
+
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil || cfg.Net == "unix" {
+		host = cfg.Addr
+	}
+	tlsConfig, err := m.tlsConfig(ctx, host)
+	if err != nil {
+		return nil, "", err
+	}
+	if tlsConfig != nil {
+		cfg.TLS = tlsConfig
+	}
+
+	dial, err := m.dialer(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if dial != nil {
+		cfg.DialFunc = dial
+	}
+
+	if m.tokenAuth() {
+		// tokens are sent as cleartext passwords, which requires TLS
+		cfg.AllowCleartextPasswords = true
+		if cfg.TLS == nil {
+			cfg.TLS = &tls.Config{ServerName: host}
+		}
+
+		// tokens are short-lived, so generate one for every new connection
+		err := cfg.Apply(mysql.BeforeConnect(func(ctx context.Context, c *mysql.Config) error {
+			token, err := m.authToken(ctx, c.Addr, c.User)
+			if err != nil {
+				return err
+			}
+			c.Passwd = token
+			return nil
+		}))
+		if err != nil {
+			return nil, "", fmt.Errorf("error configuring token authentication: %w", err)
+		}
+	}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening database connection: %w", err)
+	}
+	// queries are killed on a connection of their own, see serverConn
+	connector = killConnector{connector}
+	statements, err := d.sessionStatements(m)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(statements) > 0 {
+		connector = &sessionConnector{Connector: connector, statements: statements}
+	}
+	db := sql.OpenDB(connector)
+
+	return db, cfg.DBName, nil
+}
+
+// sessionStatements returns the statements that apply the read-only and session options to a connection
+func (d mysqlDialect) sessionStatements(m *Sql) ([]string, error) {
+	var statements []string
+	if m.ReadOnlySession {
+		statements = append(statements, "SET SESSION TRANSACTION READ ONLY")
+	}
+	if m.SessionSearchPath != "" {
+		// mysql has no search path, the closest is switching the default database
+		if strings.Contains(m.SessionSearchPath, ",") {
+			return nil, fmt.Errorf("mysql only supports a single database as the search path")
+		}
+		statements = append(statements, "USE "+d.Quote(m.SessionSearchPath))
+	}
+	if m.SessionTimezone != "" {
+		statements = append(statements, "SET time_zone = "+d.QuoteLiteral(m.SessionTimezone))
+	}
+	if m.SessionRole != "" {
+		statements = append(statements, "SET ROLE "+d.Quote(m.SessionRole))
+	}
+
+	return statements, nil
+}
+
+// DetectFlavor checks for MariaDB, TiDB and SingleStore, which speak the MySQL protocol but report themselves in the version string
+func (d mysqlDialect) DetectFlavor(ctx context.Context, db *sql.DB, _ string) (dialect, error) {
+	version, err := serverVersion(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version = strings.ToLower(version); {
+	case strings.Contains(version, "mariadb"):
+		return mariadbDialect{}, nil
+	case strings.Contains(version, "tidb"):
+		return tidbDialect{}, nil
+	}
+
+	// SingleStore reports a MySQL version, but sets its own version variable
+	rows, err := db.QueryContext(ctx, "SHOW VARIABLES LIKE 'memsql_version'")
+	if err != nil {
+		return nil, fmt.Errorf("error querying server variables: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return singlestoreDialect{}, nil
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return d, nil
+}
+
+// Retryable matches deadlocks, lock wait timeouts and lost connections
+func (mysqlDialect) Retryable(err error) bool {
+	if errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	switch mysqlErr.Number {
+	case 1205, 1213, 2006, 2013:
+		return true
+	}
+
+	return false
+}
+
+// RolledBack matches deadlocks and lock wait timeouts, which roll back the transaction or statement
+func (mysqlDialect) RolledBack(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && (mysqlErr.Number == 1205 || mysqlErr.Number == 1213)
+}
+
+func (mysqlDialect) BuildDSN(host string, port int, user, password, database string) string {
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = hostPort(host, port)
+	if strings.HasPrefix(host, "/") {
+		cfg.Net = "unix"
+		cfg.Addr = host
+	}
+	cfg.DBName = database
+	return cfg.FormatDSN()
+}
+
+func (mysqlDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	switch {
+	case analyze && format == "text":
+		// EXPLAIN ANALYZE runs the statement and only renders a tree
+		return "EXPLAIN ANALYZE " + statement, nil
+	case analyze:
+		return "", fmt.Errorf("mysql only supports explain analyze with the text format")
+	case format == "text":
+		return "EXPLAIN " + statement, nil
+	case format == "json":
+		return "EXPLAIN FORMAT=JSON " + statement, nil
+	}
+
+	return "", fmt.Errorf("unsupported explain format for mysql: %s", format)
+}
+
+func (mysqlDialect) Quote(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// QuoteLiteral escapes backslashes, which mysql reads as escapes in string literals unless NO_BACKSLASH_ESCAPES is set
+func (mysqlDialect) QuoteLiteral(value string) string {
+	return quoteLiteral(strings.ReplaceAll(value, `\`, `\\`))
+}
+
+func (mysqlDialect) VersionQuery() string { return "SELECT VERSION()" }
+
+func (d mysqlDialect) TablesQuery(database, _, _ string) string {
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s", d.QuoteLiteral(database))
+}
+
+func (d mysqlDialect) ColumnsQuery(_, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = %s", d.QuoteLiteral(table))
+}
+
+func (d mysqlDialect) ColumnDetailsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name, data_type, is_nullable, column_default, character_maximum_length, numeric_precision, numeric_scale, CASE WHEN extra LIKE '%%auto_increment%%' THEN 'YES' ELSE 'NO' END, NULLIF(column_comment, ''), ordinal_position FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position", d.QuoteLiteral(database), d.QuoteLiteral(table))
+}
+
+func (d mysqlDialect) SchemaQuery(database, _ string) string {
+	// MySQL schemas are databases, so the schema is the database of the connection
+	return fmt.Sprintf("SELECT table_name, column_name, column_type, is_nullable, column_default, ordinal_position FROM information_schema.columns WHERE table_schema = %s ORDER BY table_name, ordinal_position", d.QuoteLiteral(database))
+}
+
+func (d mysqlDialect) IndexesQuery(database, table string) (string, error) {
+	return fmt.Sprintf("SELECT index_name, column_name, CASE WHEN non_unique = 0 THEN 'YES' ELSE 'NO' END, index_type FROM information_schema.statistics WHERE table_schema = %s AND table_name = %s ORDER BY index_name, seq_in_index", d.QuoteLiteral(database), d.QuoteLiteral(table)), nil
+}
+
+func (d mysqlDialect) ForeignKeysQuery(database, _ string) (string, error) {
+	return fmt.Sprintf(`SELECT k.constraint_name, k.table_name, k.column_name,
+	CASE WHEN k.referenced_table_schema = k.table_schema THEN k.referenced_table_name ELSE CONCAT(k.referenced_table_schema, '.', k.referenced_table_name) END,
+	k.referenced_column_name, r.delete_rule, r.update_rule
+FROM information_schema.key_column_usage k
+JOIN information_schema.referential_constraints r ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name AND r.table_name = k.table_name
+WHERE k.table_schema = %s AND k.referenced_table_name IS NOT NULL
+ORDER BY k.table_name, k.constraint_name, k.ordinal_position`, d.QuoteLiteral(database)), nil
+}
+
+func (d mysqlDialect) PrimaryKeyQuery(database, table string) (string, error) {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.key_column_usage WHERE table_schema = %s AND table_name = %s AND constraint_name = 'PRIMARY' ORDER BY ordinal_position", d.QuoteLiteral(database), d.QuoteLiteral(table)), nil
+}
+
+func (d mysqlDialect) ConstraintsQuery(database, table string) (string, error) {
+	// check constraints are enforced from MySQL 8.0.16, which added information_schema.check_constraints
+	return fmt.Sprintf(`SELECT t.constraint_name, t.constraint_type, k.column_name,
+	CASE WHEN t.constraint_type = 'CHECK' THEN CONCAT('CHECK (', cc.check_clause, ')') END
+FROM information_schema.table_constraints t
+LEFT JOIN information_schema.key_column_usage k ON k.constraint_schema = t.constraint_schema AND k.constraint_name = t.constraint_name AND k.table_name = t.table_name
+LEFT JOIN information_schema.check_constraints cc ON cc.constraint_schema = t.constraint_schema AND cc.constraint_name = t.constraint_name
+WHERE t.table_schema = %s AND t.table_name = %s AND t.constraint_type IN ('CHECK', 'UNIQUE')
+ORDER BY t.constraint_name, k.ordinal_position`, d.QuoteLiteral(database), d.QuoteLiteral(table)), nil
+}
+
+func (d mysqlDialect) ViewsQuery(database, _ string) (string, error) {
+	return fmt.Sprintf("SELECT table_name, view_definition FROM information_schema.views WHERE table_schema = %s ORDER BY table_name", d.QuoteLiteral(database)), nil
+}
+
+func (d mysqlDialect) TriggersQuery(database, table string) (string, error) {
+	return fmt.Sprintf("SELECT trigger_name, action_timing, event_manipulation, action_statement FROM information_schema.triggers WHERE event_object_schema = %s AND event_object_table = %s ORDER BY trigger_name", d.QuoteLiteral(database), d.QuoteLiteral(table)), nil
+}
+
+func (d mysqlDialect) RoutinesQuery(database, _ string) (string, error) {
+	// the return value of a function is its parameter at position 0
+	return fmt.Sprintf(`SELECT r.routine_name, r.routine_type,
+	COALESCE(GROUP_CONCAT(CONCAT_WS(' ', IF(r.routine_type = 'PROCEDURE', p.parameter_mode, NULL), p.parameter_name, p.dtd_identifier) ORDER BY p.ordinal_position SEPARATOR ', '), ''),
+	IF(r.routine_type = 'FUNCTION', r.dtd_identifier, NULL), r.routine_body
+FROM information_schema.routines r
+LEFT JOIN information_schema.parameters p ON p.specific_schema = r.routine_schema AND p.specific_name = r.specific_name AND p.ordinal_position > 0
+WHERE r.routine_schema = %s
+GROUP BY r.routine_name, r.specific_name, r.routine_type, r.dtd_identifier, r.routine_body
+ORDER BY r.routine_name`, d.QuoteLiteral(database)), nil
+}
+
+func (mysqlDialect) SchemasQuery(_ string) (string, error) {
+	// MySQL schemas are databases, which have no owners
+	return "SELECT schema_name, NULL FROM information_schema.schemata WHERE schema_name NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys') ORDER BY schema_name", nil
+}
+
+// DatabasesQuery lists the schemas, which are the databases of MySQL
+func (d mysqlDialect) DatabasesQuery() (string, error) {
+	return d.SchemasQuery("")
+}
+
+// TableDDLQuery uses SHOW CREATE TABLE, which has the indexes of the table in its statement
+func (d mysqlDialect) TableDDLQuery(_, table string) (string, error) {
+	return "SHOW CREATE TABLE " + quoteQualified(d, table), nil
+}
+
+func (d mysqlDialect) RowEstimatesQuery(database, _ string) (string, error) {
+	// table_rows is exact for MyISAM and an estimate for InnoDB
+	return fmt.Sprintf("SELECT table_name, COALESCE(table_rows, 0) FROM information_schema.tables WHERE table_schema = %s AND table_type = 'BASE TABLE' ORDER BY table_name", d.QuoteLiteral(database)), nil
+}
+
+func (d mysqlDialect) TableSizesQuery(database, _ string) (string, error) {
+	// InnoDB stores large values in the pages of the table, so there is no toast
+	return fmt.Sprintf("SELECT table_name, COALESCE(data_length, 0), COALESCE(index_length, 0), 0, COALESCE(data_length, 0) + COALESCE(index_length, 0) FROM information_schema.tables WHERE table_schema = %s AND table_type = 'BASE TABLE' ORDER BY 5 DESC, table_name", d.QuoteLiteral(database)), nil
+}
+
+type mariadbDialect struct {
+	mysqlDialect
+}
+
+// ExplainQuery uses ANALYZE, which is how mariadb runs a statement and reports its plan
+func (d mariadbDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	if !analyze {
+		return d.mysqlDialect.ExplainQuery(statement, false, format)
+	}
+
+	switch format {
+	case "text":
+		return "ANALYZE " + statement, nil
+	case "json":
+		return "ANALYZE FORMAT=JSON " + statement, nil
+	}
+
+	return "", fmt.Errorf("unsupported explain format for mariadb: %s", format)
+}
+
+func (mariadbDialect) Name() string { return "mariadb" }
+
+// Detect is always false, mariadb is only reached through flavor detection on a mysql connection
+func (mariadbDialect) Detect(string) bool { return false }
+
+// tidbDialect speaks the MySQL protocol but has its own system tables and exposes every schema in the cluster
+type tidbDialect struct {
+	mysqlDialect
+}
+
+func (tidbDialect) Name() string { return "tidb" }
+
+// Detect is always false, tidb is only reached through flavor detection on a mysql connection
+func (tidbDialect) Detect(string) bool { return false }
+
+func (d tidbDialect) TablesQuery(database, _, _ string) string {
+	// TiDB lists views and sequences alongside tables
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s AND table_type = 'BASE TABLE'", d.QuoteLiteral(database))
+}
+
+func (d tidbDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position", d.QuoteLiteral(database), d.QuoteLiteral(table))
+}
+
+// singlestoreDialect speaks the MySQL protocol, its information schema also covers columnstore and rowstore tables of every database
+type singlestoreDialect struct {
+	mysqlDialect
+}
+
+func (singlestoreDialect) Name() string { return "singlestore" }
+
+// Detect is always false, singlestore is only reached through flavor detection on a mysql connection
+func (singlestoreDialect) Detect(string) bool { return false }
+
+func (singlestoreDialect) VersionQuery() string { return "SELECT @@memsql_version" }
+
+func (d singlestoreDialect) TablesQuery(database, _, _ string) string {
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s AND table_type = 'BASE TABLE'", d.QuoteLiteral(database))
+}
+
+func (d singlestoreDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position", d.QuoteLiteral(database), d.QuoteLiteral(table))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// noticeBuffer collects the notices the server sends on any connection, until they are drained
+type noticeBuffer struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (b *noticeBuffer) add(message string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, message)
+}
+
+// drain returns the collected notices and empties the buffer, a nil buffer has no notices
+func (b *noticeBuffer) drain() []string {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := b.messages
+	b.messages = nil
+	return messages
+}
+
+// querier is implemented by both connections and transactions
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// serverWarnings returns the warnings mysql raised for the last statement on a connection, which it only reports on request
+func serverWarnings(ctx context.Context, q querier, d dialect) ([]string, error) {
+	if d.Engine() != "mysql" {
+		return nil, nil
+	}
+
+	rows, err := q.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return nil, fmt.Errorf("error querying warnings: %w", err)
+	}
+	defer rows.Close()
+
+	var warnings []string
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err := rows.Scan(&level, &code, &message); err != nil {
+			return nil, fmt.Errorf("error scanning warning: %w", err)
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %d: %s", level, code, message))
+	}
+
+	return warnings, rows.Err()
+}
+
+// warnings adds the warnings of a statement in a transaction to the result
+func (r *ExecResult) warnings(ctx context.Context, tx *sql.Tx, d dialect, statement int) error {
+	warnings, err := serverWarnings(ctx, tx, d)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		r.Notices = append(r.Notices, fmt.Sprintf("statement %d: %s", statement+1, warning))
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	_ "github.com/sijms/go-ora/v2"
+)
+
+type oracleDialect struct{}
+
+func (oracleDialect) Name() string   { return "oracle" }
+func (oracleDialect) Engine() string { return "oracle" }
+
+func (oracleDialect) Detect(conn string) bool { return strings.HasPrefix(conn, "oracle://") }
+
+func (oracleDialect) Open(_ context.Context, _ *Sql, dsn string) (*sql.DB, string, error) {
+	db, err := sql.Open("oracle", dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		db.Close()
+		return nil, "", fmt.Errorf("error parsing connection string: %w", err)
+	}
+
+	// the path of an Oracle DSN is the service name
+	return db, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+func (oracleDialect) BuildDSN(host string, port int, user, password, database string) string {
+	u := url.URL{Scheme: "oracle", User: userInfo(user, password), Host: hostPort(host, port), Path: "/" + database}
+	return u.String()
+}
+
+func (oracleDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (oracleDialect) QuoteLiteral(value string) string { return quoteLiteral(value) }
+
+func (oracleDialect) VersionQuery() string {
+	return "SELECT version FROM product_component_version WHERE ROWNUM = 1"
+}
+
+func (d oracleDialect) TablesQuery(_, schema, _ string) string {
+	// Oracle schemas are users, so default to the schema of the connected user
+	if schema == "public" {
+		return "SELECT table_name FROM all_tables WHERE owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')"
+	}
+
+	return fmt.Sprintf("SELECT table_name FROM all_tables WHERE owner = UPPER(%s)", d.QuoteLiteral(schema))
+}
+
+func (d oracleDialect) ColumnsQuery(_, table string) string {
+	// the queries of a table compare its name in upper case, as Oracle stores unquoted names in upper case
+	return fmt.Sprintf("SELECT column_name FROM all_tab_columns WHERE table_name = UPPER(%s) AND owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') ORDER BY column_id", d.QuoteLiteral(table))
+}
+
+func (d oracleDialect) ColumnDetailsQuery(_, table string) string {
+	// char_length is 0 for columns that do not hold characters
+	return fmt.Sprintf(`SELECT c.column_name, c.data_type, CASE WHEN c.nullable = 'Y' THEN 'YES' ELSE 'NO' END, c.data_default, NULLIF(c.char_length, 0), c.data_precision, c.data_scale, c.identity_column, m.comments, c.column_id
+FROM all_tab_columns c
+LEFT JOIN all_col_comments m ON m.owner = c.owner AND m.table_name = c.table_name AND m.column_name = c.column_name
+WHERE c.table_name = UPPER(%s) AND c.owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') ORDER BY c.column_id`, d.QuoteLiteral(table))
+}
+
+func (oracleDialect) SchemaQuery(_, schema string) string {
+	return fmt.Sprintf("SELECT table_name, column_name, data_type, CASE WHEN nullable = 'Y' THEN 'YES' ELSE 'NO' END, data_default, column_id FROM all_tab_columns WHERE owner = %s ORDER BY table_name, column_id", oracleOwner(schema))
+}
+
+// oracleOwner returns the owner of the objects of a schema, the public schema is the schema of the connection
+func oracleOwner(schema string) string {
+	if schema == "public" {
+		return "SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')"
+	}
+
+	return fmt.Sprintf("UPPER(%s)", oracleDialect{}.QuoteLiteral(schema))
+}
+
+func (d oracleDialect) IndexesQuery(_, table string) (string, error) {
+	return fmt.Sprintf("SELECT i.index_name, c.column_name, CASE WHEN i.uniqueness = 'UNIQUE' THEN 'YES' ELSE 'NO' END, i.index_type FROM all_indexes i JOIN all_ind_columns c ON c.index_owner = i.owner AND c.index_name = i.index_name WHERE i.table_name = UPPER(%s) AND i.table_owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') ORDER BY i.index_name, c.column_position", d.QuoteLiteral(table)), nil
+}
+
+func (oracleDialect) ForeignKeysQuery(_, schema string) (string, error) {
+	// Oracle has no ON UPDATE actions
+	return fmt.Sprintf(`SELECT c.constraint_name, c.table_name, cc.column_name,
+	CASE WHEN rc.owner = c.owner THEN rc.table_name ELSE rc.owner || '.' || rc.table_name END, rcc.column_name,
+	c.delete_rule, 'NO ACTION'
+FROM all_constraints c
+JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+JOIN all_constraints rc ON rc.owner = c.r_owner AND rc.constraint_name = c.r_constraint_name
+JOIN all_cons_columns rcc ON rcc.owner = rc.owner AND rcc.constraint_name = rc.constraint_name AND rcc.position = cc.position
+WHERE c.constraint_type = 'R' AND c.owner = %s
+ORDER BY c.table_name, c.constraint_name, cc.position`, oracleOwner(schema)), nil
+}
+
+func (d oracleDialect) PrimaryKeyQuery(_, table string) (string, error) {
+	return fmt.Sprintf("SELECT cc.column_name FROM all_constraints c JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name WHERE c.constraint_type = 'P' AND c.table_name = UPPER(%s) AND c.owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') ORDER BY cc.position", d.QuoteLiteral(table)), nil
+}
+
+func (d oracleDialect) ConstraintsQuery(_, table string) (string, error) {
+	// NOT NULL columns are check constraints with generated names, they are left out
+	return fmt.Sprintf(`SELECT c.constraint_name, CASE c.constraint_type WHEN 'C' THEN 'CHECK' ELSE 'UNIQUE' END, cc.column_name,
+	CASE WHEN c.constraint_type = 'C' THEN 'CHECK (' || c.search_condition_vc || ')' END
+FROM all_constraints c
+LEFT JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+WHERE c.table_name = UPPER(%s) AND c.owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') AND c.constraint_type IN ('C', 'U')
+	AND NOT (c.constraint_type = 'C' AND c.generated = 'GENERATED NAME' AND c.search_condition_vc LIKE '%% IS NOT NULL')
+ORDER BY c.constraint_name, cc.position`, d.QuoteLiteral(table)), nil
+}
+
+func (oracleDialect) ViewsQuery(_, schema string) (string, error) {
+	return fmt.Sprintf("SELECT view_name, text_vc FROM all_views WHERE owner = %s ORDER BY view_name", oracleOwner(schema)), nil
+}
+
+func (d oracleDialect) TriggersQuery(_, table string) (string, error) {
+	// trigger_type also has the level, e.g. BEFORE EACH ROW, and triggering_event joins the events with OR
+	return fmt.Sprintf("SELECT trigger_name, CASE WHEN trigger_type LIKE 'BEFORE%%' THEN 'BEFORE' WHEN trigger_type LIKE 'AFTER%%' THEN 'AFTER' ELSE trigger_type END, triggering_event, trigger_body FROM all_triggers WHERE table_name = UPPER(%s) AND table_owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') ORDER BY trigger_name", d.QuoteLiteral(table)), nil
+}
+
+func (oracleDialect) RoutinesQuery(_, schema string) (string, error) {
+	// only standalone routines, the return value of a function is its argument at position 0
+	return fmt.Sprintf(`SELECT o.object_name, o.object_type,
+	(SELECT LISTAGG(a.argument_name || ' ' || a.in_out || ' ' || a.data_type, ', ') WITHIN GROUP (ORDER BY a.position) FROM all_arguments a WHERE a.owner = o.owner AND a.object_name = o.object_name AND a.package_name IS NULL AND a.data_level = 0 AND a.position > 0),
+	(SELECT a.data_type FROM all_arguments a WHERE a.owner = o.owner AND a.object_name = o.object_name AND a.package_name IS NULL AND a.data_level = 0 AND a.position = 0),
+	'PL/SQL'
+FROM all_objects o
+WHERE o.owner = %s AND o.object_type IN ('FUNCTION', 'PROCEDURE')
+ORDER BY o.object_name`, oracleOwner(schema)), nil
+}
+
+func (oracleDialect) SequencesQuery(_, schema string) (string, error) {
+	// last_number is ahead of the last value by the sequence cache, the default max value has more digits than a bigint
+	return fmt.Sprintf(`SELECT s.sequence_name, 'NUMBER', s.last_number, s.increment_by, GREATEST(s.min_value, -9223372036854775808), LEAST(s.max_value, 9223372036854775807),
+	(SELECT c.table_name || '.' || c.column_name FROM all_tab_identity_cols c WHERE c.owner = s.sequence_owner AND c.sequence_name = s.sequence_name)
+FROM all_sequences s
+WHERE s.sequence_owner = %s
+ORDER BY s.sequence_name`, oracleOwner(schema)), nil
+}
+
+func (oracleDialect) SchemasQuery(_ string) (string, error) {
+	// Oracle schemas are users, which own themselves
+	return "SELECT username, username FROM all_users WHERE oracle_maintained = 'N' ORDER BY username", nil
+}
+
+func (d oracleDialect) TableDDLQuery(_, table string) (string, error) {
+	// the statement of the table creates the indexes of its constraints, the other indexes follow it
+	return fmt.Sprintf(`SELECT 0, DBMS_METADATA.GET_DDL('TABLE', UPPER(%[1]s)) FROM dual
+UNION ALL
+SELECT 1, DBMS_METADATA.GET_DDL('INDEX', i.index_name, i.owner)
+FROM all_indexes i
+WHERE i.table_name = UPPER(%[1]s) AND i.table_owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')
+	AND NOT EXISTS (SELECT 1 FROM all_constraints c WHERE c.owner = i.table_owner AND c.index_name = i.index_name)
+ORDER BY 1`, d.QuoteLiteral(table)), nil
+}
+
+func (oracleDialect) RowEstimatesQuery(_, schema string) (string, error) {
+	// num_rows is set when statistics are gathered
+	return fmt.Sprintf("SELECT table_name, COALESCE(num_rows, 0) FROM all_tables WHERE owner = %s ORDER BY table_name", oracleOwner(schema)), nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ParallelQueryResult represents the outcome of a query run by RunQueriesParallel
+type ParallelQueryResult struct {
+	Query string
+	// Rows in comma-separated format
+	Rows string
+	// Error is empty when the query succeeded
+	Error string
+}
+
+// Run independent queries concurrently over the connection pool, reporting the results and errors of each query in order
+func (m *Sql) RunQueriesParallel(
+	ctx context.Context,
+	queries []string,
+	// Maximum number of queries running at the same time
+	// +default=4
+	maxConcurrency int,
+) ([]*ParallelQueryResult, error) {
+	if maxConcurrency < 1 {
+		return nil, fmt.Errorf("max concurrency must be at least 1")
+	}
+
+	// open the shared connection before the workers start
+	if !m.Duckdb {
+		if _, _, _, err := m.connect(ctx); err != nil {
+			return nil, fmt.Errorf("error opening database connection: %w", err)
+		}
+	}
+
+	results := make([]*ParallelQueryResult, len(queries))
+	var g errgroup.Group
+	g.SetLimit(maxConcurrency)
+	for i, query := range queries {
+		g.Go(func() error {
+			var rows string
+			var err error
+			if m.Duckdb {
+				rows, err = m.duckdbQuery(ctx, query, 0)
+			} else {
+				rows, err = m.query(ctx, query, queryOptions{})
+			}
+
+			results[i] = &ParallelQueryResult{Query: query, Rows: rows}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+			// a failed query is reported in its result and does not stop the others
+			return nil
+		})
+	}
+	g.Wait()
+
+	return results, nil
+}
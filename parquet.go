@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"database/sql"
+	"io"
+
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// Query the database and return the results as a snappy compressed parquet file, with column types mapped from the
+// database types
+func (m *Sql) QueryToParquet(
+	ctx context.Context,
+	query string,
+	// Gzip the file, adding .gz to its name
+	// +optional
+	compress bool,
+) (*dagger.File, error) {
+	return m.queryToFile(ctx, query, "parquet", compress, queryOptions{}, newParquetWriter)
+}
+
+// parquetWriter writes the rows into a parquet file in groups of arrowBatchSize rows
+type parquetWriter struct {
+	w       io.Writer
+	records *recordBuilder
+	file    *pqarrow.FileWriter
+}
+
+func newParquetWriter(w io.Writer, _ formatOptions) rowWriter {
+	// the parquet writer closes its output when it is an io.Closer, the file is closed by its owner
+	return &parquetWriter{w: struct{ io.Writer }{w}}
+}
+
+func (p *parquetWriter) header(columns []*sql.ColumnType) error {
+	p.records = newRecordBuilder(columns)
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	file, err := pqarrow.NewFileWriter(p.records.schema, p.w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+	p.file = file
+
+	return nil
+}
+
+func (p *parquetWriter) row(values []any) error {
+	if err := p.records.append(values); err != nil {
+		return err
+	}
+	if p.records.rows < arrowBatchSize {
+		return nil
+	}
+
+	return p.write()
+}
+
+func (p *parquetWriter) write() error {
+	record := p.records.record()
+	defer record.Release()
+
+	return p.file.Write(record)
+}
+
+func (p *parquetWriter) flush() error {
+	defer p.records.release()
+
+	if p.records.rows > 0 {
+		if err := p.write(); err != nil {
+			return err
+		}
+	}
+
+	return p.file.Close()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// statementType matches policy rules that name a statement type, e.g. DROP or CREATE TABLE, rules with other characters are regular expressions
+var statementType = regexp.MustCompile(`^[A-Za-z]+( [A-Za-z]+)*$`)
+
+// Restrict the statements every execution function runs, deny rules take precedence and an empty allow list allows everything else
+func (m *Sql) WithPolicy(
+	// Statement types (e.g. SELECT, CREATE TABLE) or case-insensitive regular expressions a statement must match
+	// +optional
+	allow []string,
+	// Statement types (e.g. DROP, TRUNCATE) or case-insensitive regular expressions a statement must not match
+	// +optional
+	deny []string,
+) (*Sql, error) {
+	for _, rule := range append(append([]string{}, allow...), deny...) {
+		if _, err := policyRule(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	m.PolicyAllow = allow
+	m.PolicyDeny = deny
+	return m, nil
+}
+
+// policyRule compiles a rule into a function that reports whether a statement matches it
+func policyRule(rule string) (func(statement, engine string) bool, error) {
+	rule = strings.TrimSpace(rule)
+	if statementType.MatchString(rule) {
+		keywords := strings.Fields(strings.ToUpper(rule))
+		return func(statement, engine string) bool {
+			words := statementWords(statement, engine)
+			if len(words) < len(keywords) {
+				return false
+			}
+			for i, keyword := range keywords {
+				if words[i] != keyword {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + rule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy rule %q: %w", rule, err)
+	}
+
+	return func(statement, _ string) bool { return re.MatchString(statement) }, nil
+}
+
+// checkPolicy returns an error for the first statement the policy does not permit, each argument is split into its
+// statements as most drivers run every statement of a string
+func (m *Sql) checkPolicy(engine string, scripts ...string) error {
+	if len(m.PolicyAllow) == 0 && len(m.PolicyDeny) == 0 {
+		return nil
+	}
+
+	var statements []string
+	for _, script := range scripts {
+		statements = append(statements, splitStatements(script, engine)...)
+	}
+
+	for _, statement := range statements {
+		for _, rule := range m.PolicyDeny {
+			matches, err := policyRule(rule)
+			if err != nil {
+				return err
+			}
+			if matches(statement, engine) {
+				return fmt.Errorf("statement denied by policy rule %q", rule)
+			}
+		}
+
+		allowed := len(m.PolicyAllow) == 0
+		for _, rule := range m.PolicyAllow {
+			matches, err := policyRule(rule)
+			if err != nil {
+				return err
+			}
+			if matches(statement, engine) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("statement not allowed by policy, allowed are %s", strings.Join(m.PolicyAllow, ", "))
+		}
+	}
+
+	return nil
+}
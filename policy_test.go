@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestCheckPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		allow     []string
+		deny      []string
+		engine    string
+		statement string
+		wantErr   bool
+	}{
+		{name: "no policy", statement: "DROP TABLE users"},
+		{name: "denied type", deny: []string{"DROP"}, statement: "drop table users", wantErr: true},
+		{name: "other type", deny: []string{"DROP"}, statement: "SELECT 1"},
+		{name: "multi-word type", deny: []string{"CREATE TABLE"}, statement: "CREATE INDEX idx ON users (id)"},
+		{name: "multi-word type matches", deny: []string{"CREATE TABLE"}, statement: "create  table t (id int)", wantErr: true},
+		{name: "leading comment", deny: []string{"DROP"}, statement: "/* cleanup */ DROP TABLE users", wantErr: true},
+		{name: "keyword in literal", deny: []string{"DROP"}, statement: "SELECT 'DROP TABLE users'"},
+		{name: "allowed type", allow: []string{"SELECT"}, statement: "SELECT 1"},
+		{name: "not allowed type", allow: []string{"SELECT"}, statement: "DELETE FROM users", wantErr: true},
+		{name: "deny takes precedence", allow: []string{"SELECT"}, deny: []string{`pg_sleep`}, statement: "SELECT pg_sleep(10)", wantErr: true},
+		{name: "regular expression", deny: []string{`delete\s+from\s+\w+\s*$`}, statement: "DELETE FROM users", wantErr: true},
+		{name: "regular expression with where", deny: []string{`delete\s+from\s+\w+\s*$`}, statement: "DELETE FROM users WHERE id = 1"},
+		{name: "denied second statement", deny: []string{"DROP"}, statement: "SELECT 1; DROP TABLE users", wantErr: true},
+		{name: "not allowed second statement", allow: []string{"SELECT"}, statement: "SELECT 1; DROP TABLE users", wantErr: true},
+		{name: "allowed statements", allow: []string{"SELECT"}, statement: "SELECT 1; SELECT 2;"},
+		{name: "delimiter in literal", deny: []string{"DROP"}, statement: "SELECT ';DROP TABLE users'"},
+		{name: "delimiter in dollar quote", allow: []string{"CREATE FUNCTION"}, engine: "postgres", statement: "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql"},
+		{name: "mysql delimiter directive", deny: []string{"DROP"}, engine: "mysql", statement: "DELIMITER //\nSELECT 1 //\nDROP TABLE users //", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Sql{PolicyAllow: tt.allow, PolicyDeny: tt.deny}
+			engine := tt.engine
+			if engine == "" {
+				engine = "postgres"
+			}
+
+			err := m.checkPolicy(engine, tt.statement)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkPolicy(%q) error = %v, want error %v", tt.statement, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPolicyChecksEveryArgument(t *testing.T) {
+	m := &Sql{PolicyDeny: []string{"TRUNCATE"}}
+	if err := m.checkPolicy("postgres", "SELECT 1", "TRUNCATE users"); err == nil {
+		t.Fatal("checkPolicy allowed a denied statement in its second argument")
+	}
+}
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Use the simple query protocol without prepared statements, required behind transaction pooling proxies such as PgBouncer (postgres only)
+func (m *Sql) WithSimpleProtocol() *Sql {
+	m.SimpleProtocol = true
+	return m
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string   { return "postgres" }
+func (postgresDialect) Engine() string { return "postgres" }
+
+func (postgresDialect) Detect(conn string) bool {
+	return strings.HasPrefix(conn, "postgres://") || strings.HasPrefix(conn, "postgresql://") || strings.Contains(conn, "user=") && strings.Contains(conn, "dbname=")
+}
+
+func (d postgresDialect) Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error) {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error parsing connection string: %w", err)
+	}
+
+	tlsConfig, err := m.tlsConfig(ctx, cfg.Host)
+	if err != nil {
+		return nil, "", err
+	}
+	if tlsConfig != nil {
+		cfg.TLSConfig = tlsConfig
+		cfg.Fallbacks = nil
+	}
+
+	if m.notices == nil {
+		m.notices = &noticeBuffer{}
+	}
+	notices := m.notices
+	cfg.OnNotice = func(_ *pgconn.PgConn, n *pgconn.Notice) {
+		notices.add(fmt.Sprintf("%s: %s", n.Severity, n.Message))
+	}
+
+	// send a cancel request when the context of a query ends, instead of only closing the socket which leaves the query running
+	cfg.BuildContextWatcherHandler = func(pgConn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{Conn: pgConn, DeadlineDelay: killTimeout}
+	}
+
+	if m.SimpleProtocol {
+		// prepared statements live on a server connection, which the proxy may swap between statements
+		cfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+		cfg.StatementCacheCapacity = 0
+		cfg.DescriptionCacheCapacity = 0
+	}
+
+	if m.ReadOnlySession {
+		cfg.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+
+	dial, err := m.dialer(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if dial != nil {
+		// leave resolving the database host to the dialer
+		cfg.DialFunc = pgconn.DialFunc(dial)
+		cfg.LookupFunc = func(_ context.Context, host string) ([]string, error) { return []string{host}, nil }
+	}
+
+	var opts []stdlib.OptionOpenDB
+	if m.tokenAuth() {
+		// tokens are short-lived, so generate one for every new connection
+		opts = append(opts, stdlib.OptionBeforeConnect(func(ctx context.Context, cc *pgx.ConnConfig) error {
+			token, err := m.authToken(ctx, net.JoinHostPort(cc.Host, strconv.Itoa(int(cc.Port))), cc.User)
+			if err != nil {
+				return err
+			}
+			cc.Password = token
+			return nil
+		}))
+	}
+
+	connector := stdlib.GetConnector(*cfg, opts...)
+	if statements := d.sessionStatements(m); len(statements) > 0 {
+		connector = &sessionConnector{Connector: connector, statements: statements}
+	}
+
+	return sql.OpenDB(connector), cfg.Database, nil
+}
+
+// sessionStatements returns the statements that apply the session options to a connection
+func (d postgresDialect) sessionStatements(m *Sql) []string {
+	var statements []string
+	if m.SessionSearchPath != "" {
+		schemas := strings.Split(m.SessionSearchPath, ",")
+		for i, schema := range schemas {
+			schemas[i] = d.Quote(strings.TrimSpace(schema))
+		}
+		statements = append(statements, "SET search_path TO "+strings.Join(schemas, ", "))
+	}
+	if m.SessionTimezone != "" {
+		statements = append(statements, "SET TIME ZONE "+d.QuoteLiteral(m.SessionTimezone))
+	}
+	if m.SessionRole != "" {
+		statements = append(statements, "SET ROLE "+d.Quote(m.SessionRole))
+	}
+
+	return statements
+}
+
+// DetectFlavor checks for postgres compatible engines, which are only recognizable in version()
+func (d postgresDialect) DetectFlavor(ctx context.Context, db *sql.DB, _ string) (dialect, error) {
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
+		return nil, fmt.Errorf("error querying server version: %w", err)
+	}
+
+	switch version = strings.ToLower(version); {
+	case strings.Contains(version, "redshift"):
+		return redshiftDialect{}, nil
+	case strings.Contains(version, "-yb-"):
+		return yugabyteDialect{}, nil
+	}
+
+	return d, nil
+}
+
+// Retryable matches serialization failures, deadlocks, connection exceptions and servers that are starting or shutting down
+func (postgresDialect) Retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case "40001", "40P01", "57P01", "57P02", "57P03":
+		return true
+	}
+
+	// class 08 is connection exceptions
+	return strings.HasPrefix(pgErr.Code, "08")
+}
+
+// RolledBack matches serialization failures and deadlocks, which abort the statement, and errors pgx raises before
+// anything was sent to the server
+func (postgresDialect) RolledBack(err error) bool {
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+func (postgresDialect) BuildDSN(host string, port int, user, password, database string) string {
+	u := url.URL{Scheme: "postgres", User: userInfo(user, password), Host: hostPort(host, port), Path: "/" + database}
+
+	// a path is the directory of the unix socket, which pgx takes as a query parameter
+	if strings.HasPrefix(host, "/") {
+		u.Host = ""
+		params := url.Values{"host": {host}}
+		if port != 0 {
+			params.Set("port", strconv.Itoa(port))
+		}
+		u.RawQuery = params.Encode()
+	}
+
+	return u.String()
+}
+
+func (postgresDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	switch format {
+	case "text", "json", "yaml", "xml":
+	default:
+		return "", fmt.Errorf("unsupported explain format for postgres: %s", format)
+	}
+
+	options := []string{"FORMAT " + strings.ToUpper(format)}
+	if analyze {
+		options = append([]string{"ANALYZE"}, options...)
+	}
+
+	return fmt.Sprintf("EXPLAIN (%s) %s", strings.Join(options, ", "), statement), nil
+}
+
+func (postgresDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (postgresDialect) QuoteLiteral(value string) string { return quoteLiteral(value) }
+
+func (postgresDialect) VersionQuery() string { return "SHOW server_version" }
+
+func (d postgresDialect) TablesQuery(database, schema, _ string) string {
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s AND table_catalog = %s", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d postgresDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = %s AND table_catalog = %s", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d postgresDialect) ColumnDetailsQuery(database, table string) string {
+	// the table is looked up in the current schema, as tables of other schemas can have the same name
+	return fmt.Sprintf("SELECT column_name, data_type, is_nullable, column_default, character_maximum_length, numeric_precision, numeric_scale, CASE WHEN is_identity = 'YES' OR column_default LIKE 'nextval(%%' THEN 'YES' ELSE 'NO' END, col_description(format('%%I.%%I', table_schema, table_name)::regclass, ordinal_position), ordinal_position FROM information_schema.columns WHERE table_name = %s AND table_schema = current_schema() AND table_catalog = %s ORDER BY ordinal_position", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d postgresDialect) SchemaQuery(database, schema string) string {
+	return fmt.Sprintf("SELECT table_name, column_name, data_type, is_nullable, column_default, ordinal_position FROM information_schema.columns WHERE table_schema = %s AND table_catalog = %s ORDER BY table_name, ordinal_position", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d postgresDialect) IndexesQuery(_, table string) (string, error) {
+	// regclass resolves the table through the search path, pg_get_indexdef also returns the expressions of expression indexes
+	return fmt.Sprintf(`SELECT i.relname, pg_get_indexdef(ix.indexrelid, k.position, true), CASE WHEN ix.indisunique THEN 'YES' ELSE 'NO' END, am.amname
+FROM pg_index ix
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_am am ON am.oid = i.relam
+CROSS JOIN LATERAL generate_series(1, ix.indnkeyatts) AS k(position)
+WHERE ix.indrelid = %s::regclass
+ORDER BY i.relname, k.position`, d.QuoteLiteral(table)), nil
+}
+
+func (d postgresDialect) ForeignKeysQuery(_, schema string) (string, error) {
+	return fmt.Sprintf(`SELECT c.conname, t.relname, a.attname,
+	CASE WHEN rn.nspname = n.nspname THEN rt.relname ELSE rn.nspname || '.' || rt.relname END, ra.attname,
+	CASE c.confdeltype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END,
+	CASE c.confupdtype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_class rt ON rt.oid = c.confrelid
+JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refattnum, position)
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refattnum
+WHERE c.contype = 'f' AND n.nspname = %s
+ORDER BY t.relname, c.conname, k.position`, d.QuoteLiteral(schema)), nil
+}
+
+func (d postgresDialect) PrimaryKeyQuery(_, table string) (string, error) {
+	return fmt.Sprintf(`SELECT a.attname
+FROM pg_constraint c
+CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, position)
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+WHERE c.conrelid = %s::regclass AND c.contype = 'p'
+ORDER BY k.position`, d.QuoteLiteral(table)), nil
+}
+
+func (d postgresDialect) ConstraintsQuery(_, table string) (string, error) {
+	return fmt.Sprintf(`SELECT c.conname, CASE c.contype WHEN 'c' THEN 'CHECK' WHEN 'u' THEN 'UNIQUE' ELSE 'EXCLUDE' END, a.attname, pg_get_constraintdef(c.oid, true)
+FROM pg_constraint c
+LEFT JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, position) ON true
+LEFT JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+WHERE c.conrelid = %s::regclass AND c.contype IN ('c', 'u', 'x')
+ORDER BY c.conname, k.position`, d.QuoteLiteral(table)), nil
+}
+
+func (d postgresDialect) ViewsQuery(_, schema string) (string, error) {
+	return fmt.Sprintf("SELECT viewname, definition FROM pg_views WHERE schemaname = %s ORDER BY viewname", d.QuoteLiteral(schema)), nil
+}
+
+func (d postgresDialect) TriggersQuery(_, table string) (string, error) {
+	// tgtype is a bit mask of the timing and the events of a trigger, internal triggers enforce foreign keys
+	return fmt.Sprintf(`SELECT t.tgname, CASE WHEN t.tgtype & 2 <> 0 THEN 'BEFORE' WHEN t.tgtype & 64 <> 0 THEN 'INSTEAD OF' ELSE 'AFTER' END, e.event, t.tgfoid::regproc::text
+FROM pg_trigger t
+CROSS JOIN LATERAL (VALUES (4, 'INSERT'), (8, 'DELETE'), (16, 'UPDATE'), (32, 'TRUNCATE')) AS e(bit, event)
+WHERE t.tgrelid = %s::regclass AND NOT t.tgisinternal AND t.tgtype & e.bit <> 0
+ORDER BY t.tgname, e.bit`, d.QuoteLiteral(table)), nil
+}
+
+func (d postgresDialect) RoutinesQuery(_, schema string) (string, error) {
+	return fmt.Sprintf(`SELECT p.proname, CASE p.prokind WHEN 'p' THEN 'PROCEDURE' WHEN 'a' THEN 'AGGREGATE' WHEN 'w' THEN 'WINDOW' ELSE 'FUNCTION' END,
+	pg_get_function_arguments(p.oid), CASE WHEN p.prokind <> 'p' THEN pg_get_function_result(p.oid) END, l.lanname
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+JOIN pg_language l ON l.oid = p.prolang
+WHERE n.nspname = %s
+ORDER BY p.proname, pg_get_function_arguments(p.oid)`, d.QuoteLiteral(schema)), nil
+}
+
+func (d postgresDialect) SequencesQuery(_, schema string) (string, error) {
+	// serial columns own their sequence with an auto dependency, identity columns with an internal one
+	return fmt.Sprintf(`SELECT s.sequencename, s.data_type::text, s.last_value, s.increment_by, s.min_value, s.max_value,
+	COALESCE(d.refobjid::regclass::text || '.' || a.attname, '')
+FROM pg_sequences s
+LEFT JOIN pg_depend d ON d.objid = format('%%I.%%I', s.schemaname, s.sequencename)::regclass AND d.classid = 'pg_class'::regclass AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+WHERE s.schemaname = %s
+ORDER BY s.sequencename`, d.QuoteLiteral(schema)), nil
+}
+
+func (postgresDialect) SchemasQuery(_ string) (string, error) {
+	// pg_namespace lists every schema, so the ones the connection cannot use are left out
+	return `SELECT n.nspname, pg_get_userbyid(n.nspowner) FROM pg_namespace n WHERE n.nspname NOT LIKE 'pg\_%' AND n.nspname <> 'information_schema' AND has_schema_privilege(n.oid, 'USAGE') ORDER BY n.nspname`, nil
+}
+
+func (postgresDialect) DatabasesQuery() (string, error) {
+	return "SELECT datname, pg_get_userbyid(datdba) FROM pg_database WHERE NOT datistemplate AND datallowconn ORDER BY datname", nil
+}
+
+func (d postgresDialect) TableDDLQuery(_, table string) (string, error) {
+	// postgres has no SHOW CREATE TABLE, so the statement is built from the catalog like pg_dump does, the indexes
+	// behind primary key, unique and exclusion constraints are created by their constraints
+	return fmt.Sprintf(`WITH t AS (SELECT %s::regclass AS oid),
+lines AS (
+	SELECT a.attnum AS position, quote_ident(a.attname) || ' ' || format_type(a.atttypid, a.atttypmod)
+		|| CASE a.attidentity WHEN 'a' THEN ' GENERATED ALWAYS AS IDENTITY' WHEN 'd' THEN ' GENERATED BY DEFAULT AS IDENTITY' ELSE '' END
+		|| CASE WHEN a.attgenerated = 's' THEN ' GENERATED ALWAYS AS (' || pg_get_expr(d.adbin, d.adrelid) || ') STORED' WHEN d.adbin IS NOT NULL THEN ' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid) ELSE '' END
+		|| CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END AS line
+	FROM t
+	JOIN pg_attribute a ON a.attrelid = t.oid
+	LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+	WHERE a.attnum > 0 AND NOT a.attisdropped
+	UNION ALL
+	SELECT 10000 + row_number() OVER (ORDER BY c.contype <> 'p', c.conname), 'CONSTRAINT ' || quote_ident(c.conname) || ' ' || pg_get_constraintdef(c.oid, true)
+	FROM t
+	JOIN pg_constraint c ON c.conrelid = t.oid
+	WHERE c.contype IN ('p', 'u', 'f', 'c', 'x')
+)
+SELECT 0, 'CREATE TABLE ' || t.oid::text || E' (\n' || (SELECT string_agg('    ' || line, E',\n' ORDER BY position) FROM lines) || E'\n);' FROM t
+UNION ALL
+SELECT 1, pg_get_indexdef(i.indexrelid) || ';'
+FROM t
+JOIN pg_index i ON i.indrelid = t.oid
+WHERE NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid AND c.conrelid = t.oid AND c.contype IN ('p', 'u', 'x'))
+ORDER BY 1, 2`, d.QuoteLiteral(table)), nil
+}
+
+func (d postgresDialect) RowEstimatesQuery(_, schema string) (string, error) {
+	// reltuples is -1 for tables that were never vacuumed or analyzed
+	return fmt.Sprintf("SELECT c.relname, GREATEST(c.reltuples, 0)::bigint FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE n.nspname = %s AND c.relkind IN ('r', 'p', 'm') ORDER BY c.relname", d.QuoteLiteral(schema)), nil
+}
+
+func (d postgresDialect) TableSizesQuery(_, schema string) (string, error) {
+	// the total also has the free space and visibility maps of the table
+	return fmt.Sprintf("SELECT c.relname, pg_relation_size(c.oid), pg_indexes_size(c.oid), COALESCE(pg_total_relation_size(NULLIF(c.reltoastrelid, 0)), 0), pg_total_relation_size(c.oid) FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE n.nspname = %s AND c.relkind IN ('r', 'm') ORDER BY pg_total_relation_size(c.oid) DESC, c.relname", d.QuoteLiteral(schema)), nil
+}
+
+// redshiftDialect speaks the postgres protocol but has its own system views
+type redshiftDialect struct {
+	postgresDialect
+}
+
+// ExplainQuery only supports text plans, redshift has no EXPLAIN ANALYZE or FORMAT option
+func (redshiftDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	if analyze || format != "text" {
+		return "", fmt.Errorf("redshift only supports explaining text plans without analyze")
+	}
+
+	return "EXPLAIN " + statement, nil
+}
+
+func (redshiftDialect) Name() string { return "redshift" }
+
+func (redshiftDialect) Detect(conn string) bool {
+	return strings.HasPrefix(conn, "redshift://") || strings.Contains(conn, ".redshift.amazonaws.com") || strings.Contains(conn, ".redshift-serverless.amazonaws.com")
+}
+
+func (d redshiftDialect) Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error) {
+	// swap the scheme so the pgx driver accepts the connection string
+	if strings.HasPrefix(strings.ToLower(dsn), "redshift://") {
+		dsn = "postgres://" + dsn[len("redshift://"):]
+	}
+
+	return d.postgresDialect.Open(ctx, m, dsn)
+}
+
+func (d redshiftDialect) DetectFlavor(context.Context, *sql.DB, string) (dialect, error) {
+	return d, nil
+}
+
+func (redshiftDialect) VersionQuery() string { return "SELECT version()" }
+
+func (d redshiftDialect) TablesQuery(database, schema, _ string) string {
+	return fmt.Sprintf("SELECT table_name FROM svv_tables WHERE table_schema = %s AND table_catalog = %s AND table_type = 'BASE TABLE'", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d redshiftDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM svv_columns WHERE table_name = %s AND table_catalog = %s ORDER BY ordinal_position", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d redshiftDialect) ColumnDetailsQuery(database, table string) string {
+	// the default of an identity column is "identity"(table oid, column, seed and step)
+	return fmt.Sprintf(`SELECT column_name, data_type, is_nullable, column_default, character_maximum_length, numeric_precision, numeric_scale, CASE WHEN column_default LIKE '"identity"%%' THEN 'YES' ELSE 'NO' END, remarks, ordinal_position FROM svv_columns WHERE table_name = %s AND table_schema = current_schema() AND table_catalog = %s ORDER BY ordinal_position`, d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d redshiftDialect) SchemaQuery(database, schema string) string {
+	return fmt.Sprintf("SELECT table_name, column_name, data_type, is_nullable, column_default, ordinal_position FROM svv_columns WHERE table_schema = %s AND table_catalog = %s ORDER BY table_name, ordinal_position", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+// IndexesQuery returns an error, redshift has no indexes and orders data with sort keys instead
+func (redshiftDialect) IndexesQuery(_, _ string) (string, error) {
+	return "", fmt.Errorf("redshift has no indexes, tables are ordered by their sort keys")
+}
+
+// ForeignKeysQuery returns an error, the catalog of redshift cannot expand the columns of a constraint
+func (redshiftDialect) ForeignKeysQuery(_, _ string) (string, error) {
+	return "", fmt.Errorf("listing foreign keys is not supported for redshift")
+}
+
+// PrimaryKeyQuery reads information_schema, redshift cannot unnest the columns of a constraint
+func (d redshiftDialect) PrimaryKeyQuery(database, table string) (string, error) {
+	return fmt.Sprintf("SELECT k.column_name FROM information_schema.table_constraints c JOIN information_schema.key_column_usage k ON k.constraint_schema = c.constraint_schema AND k.constraint_name = c.constraint_name WHERE c.constraint_type = 'PRIMARY KEY' AND c.table_name = %s AND c.table_catalog = %s ORDER BY k.ordinal_position", d.QuoteLiteral(table), d.QuoteLiteral(database)), nil
+}
+
+// ConstraintsQuery returns an error, redshift has no check constraints and does not enforce unique constraints
+func (redshiftDialect) ConstraintsQuery(_, _ string) (string, error) {
+	return "", fmt.Errorf("listing constraints is not supported for redshift")
+}
+
+// TriggersQuery returns an error, redshift has no triggers
+func (redshiftDialect) TriggersQuery(_, _ string) (string, error) {
+	return "", fmt.Errorf("listing triggers is not supported for redshift")
+}
+
+// RoutinesQuery returns an error, the pg_proc of redshift predates the kinds of routines
+func (redshiftDialect) RoutinesQuery(_, _ string) (string, error) {
+	return "", fmt.Errorf("listing routines is not supported for redshift")
+}
+
+// SequencesQuery returns an error, redshift has identity columns instead of sequences
+func (redshiftDialect) SequencesQuery(_, _ string) (string, error) {
+	return "", fmt.Errorf("listing sequences is not supported for redshift")
+}
+
+// DatabasesQuery reads svv_redshift_databases, which also lists the databases shared with the cluster
+func (redshiftDialect) DatabasesQuery() (string, error) {
+	return "SELECT d.database_name, u.usename FROM svv_redshift_databases d LEFT JOIN pg_user u ON u.usesysid = d.database_owner ORDER BY d.database_name", nil
+}
+
+// TableDDLQuery uses SHOW TABLE, which has the distribution and sort keys pg_catalog does not
+func (d redshiftDialect) TableDDLQuery(_, table string) (string, error) {
+	return "SHOW TABLE " + quoteQualified(d, table), nil
+}
+
+func (d redshiftDialect) RowEstimatesQuery(_, schema string) (string, error) {
+	return fmt.Sprintf(`SELECT "table", tbl_rows::bigint FROM svv_table_info WHERE "schema" = %s ORDER BY "table"`, d.QuoteLiteral(schema)), nil
+}
+
+func (d redshiftDialect) TableSizesQuery(_, schema string) (string, error) {
+	// size is the number of 1 MB blocks of the table, which redshift does not split into data and indexes
+	return fmt.Sprintf(`SELECT "table", size::bigint * 1048576, 0, 0, size::bigint * 1048576 FROM svv_table_info WHERE "schema" = %s ORDER BY size DESC, "table"`, d.QuoteLiteral(schema)), nil
+}
+
+// yugabyteDialect speaks the postgres protocol, its catalog includes tablet and tablegroup relations
+type yugabyteDialect struct {
+	postgresDialect
+}
+
+func (yugabyteDialect) Name() string { return "yugabyte" }
+
+func (yugabyteDialect) Detect(conn string) bool { return strings.HasPrefix(conn, "yugabyte://") }
+
+func (d yugabyteDialect) Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error) {
+	// swap the scheme so the pgx driver accepts the connection string
+	if strings.HasPrefix(strings.ToLower(dsn), "yugabyte://") {
+		dsn = "postgres://" + dsn[len("yugabyte://"):]
+	}
+
+	return d.postgresDialect.Open(ctx, m, dsn)
+}
+
+func (d yugabyteDialect) DetectFlavor(context.Context, *sql.DB, string) (dialect, error) {
+	return d, nil
+}
+
+// VersionQuery uses version() as server_version only reports the postgres version YugabyteDB is based on
+func (yugabyteDialect) VersionQuery() string { return "SELECT version()" }
+
+func (d yugabyteDialect) TablesQuery(database, schema, _ string) string {
+	// only list regular tables, skipping the views and system relations YugabyteDB adds to the catalog
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s AND table_catalog = %s AND table_type = 'BASE TABLE'", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d yugabyteDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = %s AND table_catalog = %s AND table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY ordinal_position", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
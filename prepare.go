@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PreparedStatement represents a statement registered under a name
+type PreparedStatement struct {
+	Name  string
+	Query string
+}
+
+// Register a parameterized statement under a name, using the placeholders of the database (e.g. $1, ? or @p1)
+func (m *Sql) Prepare(name string, query string) *Sql {
+	for _, p := range m.PreparedStatements {
+		if p.Name == name {
+			p.Query = query
+			return m
+		}
+	}
+
+	m.PreparedStatements = append(m.PreparedStatements, &PreparedStatement{Name: name, Query: query})
+	return m
+}
+
+// Execute a prepared statement once for every set of arguments, which the server plans only once
+func (m *Sql) ExecutePrepared(
+	ctx context.Context,
+	name string,
+	// JSON array of values for a single execution, e.g. [1, "a"], or an array of arrays for one execution per element
+	// +default="[]"
+	argsJSON string,
+) (*ExecResult, error) {
+	if m.Duckdb {
+		return nil, fmt.Errorf("prepared statements are not supported for duckdb")
+	}
+
+	var query string
+	for _, p := range m.PreparedStatements {
+		if p.Name == name {
+			query = p.Query
+		}
+	}
+	if query == "" {
+		return nil, fmt.Errorf("no prepared statement named %s, use prepare first", name)
+	}
+
+	batches, err := argBatches(argsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	if err := m.checkPolicy(d.Engine(), query); err != nil {
+		return nil, err
+	}
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	conn, release, err := serverConn(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	stmt, err := conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing statement %s: %w", name, err)
+	}
+	defer stmt.Close()
+
+	total := &ExecResult{}
+	for i, args := range batches {
+		result, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error executing %s with arguments %d after %d rows: %w", name, i+1, total.RowsAffected, err)
+		}
+		total.add(result)
+	}
+
+	return total, nil
+}
+
+// argBatches decodes a JSON array of bind parameters, or an array of them, into one set of arguments per execution
+func argBatches(argsJSON string) ([][]any, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(argsJSON), &raw); err != nil {
+		return nil, fmt.Errorf("error parsing JSON arguments: %w", err)
+	}
+
+	// a flat array, including an empty one, is a single execution
+	if len(raw) == 0 || !strings.HasPrefix(strings.TrimSpace(string(raw[0])), "[") {
+		args, err := jsonArgs(argsJSON)
+		if err != nil {
+			return nil, err
+		}
+		return [][]any{args}, nil
+	}
+
+	batches := make([][]any, len(raw))
+	for i, r := range raw {
+		args, err := jsonArgs(string(r))
+		if err != nil {
+			return nil, fmt.Errorf("arguments %d: %w", i+1, err)
+		}
+		batches[i] = args
+	}
+
+	return batches, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// primaryKeyLister is implemented by dialects that can list the primary key of a table
+type primaryKeyLister interface {
+	// PrimaryKeyQuery lists the columns of the primary key of a table, ordered by their position in the key
+	PrimaryKeyQuery(database, table string) (string, error)
+}
+
+// Return the columns of the primary key of a table in key order, empty when the table has no primary key
+func (m *Sql) PrimaryKey(ctx context.Context, table string) ([]string, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(primaryKeyLister)
+	if !ok {
+		return nil, fmt.Errorf("listing primary keys is not supported for %s", d.Name())
+	}
+	query, err := l.PrimaryKeyQuery(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying primary key: %w", err)
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return columns, nil
+}
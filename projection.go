@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// projectionWriter selects, orders and drops the columns of the results before they reach a writer
+type projectionWriter struct {
+	w       rowWriter
+	columns []string
+	exclude []string
+	indexes []int
+}
+
+func (p *projectionWriter) header(columns []*sql.ColumnType) error {
+	names := columnNames(columns)
+
+	if len(p.columns) > 0 {
+		for _, name := range p.columns {
+			i := columnIndex(names, name)
+			if i < 0 {
+				return fmt.Errorf("column %q is not in the results, the columns are %s", name, strings.Join(names, ", "))
+			}
+			p.indexes = append(p.indexes, i)
+		}
+	} else {
+		for i := range names {
+			p.indexes = append(p.indexes, i)
+		}
+	}
+
+	excluded := make(map[int]bool, len(p.exclude))
+	for _, name := range p.exclude {
+		// a misspelled column would otherwise leak the column it meant to hide
+		i := columnIndex(names, name)
+		if i < 0 {
+			return fmt.Errorf("excluded column %q is not in the results, the columns are %s", name, strings.Join(names, ", "))
+		}
+		excluded[i] = true
+	}
+
+	projected := make([]*sql.ColumnType, 0, len(p.indexes))
+	indexes := p.indexes[:0]
+	for _, i := range p.indexes {
+		if excluded[i] {
+			continue
+		}
+		indexes = append(indexes, i)
+		projected = append(projected, columns[i])
+	}
+	p.indexes = indexes
+
+	return p.w.header(projected)
+}
+
+func (p *projectionWriter) row(values []any) error {
+	projected := make([]any, len(p.indexes))
+	for n, i := range p.indexes {
+		projected[n] = values[i]
+	}
+
+	return p.w.row(projected)
+}
+
+func (p *projectionWriter) flush() error {
+	return p.w.flush()
+}
+
+// setDialect passes the dialect on to writers that depend on it
+func (p *projectionWriter) setDialect(d dialect) {
+	if dw, ok := p.w.(dialectWriter); ok {
+		dw.setDialect(d)
+	}
+}
+
+// columnIndex returns the index of a column by name, ignoring case as databases differ in how they fold names, or -1
+func columnIndex(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+
+	return -1
+}
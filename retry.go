@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+// Retry connecting when it fails with a transient error such as a connection reset, and statements and transactions
+// when the server rolled them back, such as after a deadlock or serialization failure
+func (m *Sql) WithRetry(
+	// Number of retries after the first attempt fails
+	// +default=3
+	attempts int,
+	// Seconds to wait before the first retry, doubling for every retry after it
+	// +default=1
+	backoffSeconds int,
+) *Sql {
+	m.RetryAttempts = attempts
+	m.RetryBackoff = backoffSeconds
+	return m
+}
+
+// retryClassifier is implemented by dialects that recognize the transient errors of their engine
+type retryClassifier interface {
+	// Retryable reports whether an error is likely to succeed when the statement runs again
+	Retryable(err error) bool
+	// RolledBack reports whether a statement that failed with an error did not take effect on the server, e.g. as the
+	// victim of a deadlock, so running it again cannot apply it twice
+	RolledBack(err error) bool
+}
+
+// retry calls fn until it succeeds, fails with an error that is not transient or runs out of attempts.
+// A nil dialect checks the errors of every registered dialect, e.g. while connecting.
+func (m *Sql) retry(ctx context.Context, d dialect, fn func() error) error {
+	return m.retryIf(ctx, func(err error) bool { return retryable(d, err) }, fn)
+}
+
+// retryStatement calls fn like retry, but only retries errors after which the statement did not take effect, as a
+// statement may have run before its connection was lost
+func (m *Sql) retryStatement(ctx context.Context, d dialect, fn func() error) error {
+	return m.retryIf(ctx, func(err error) bool { return retryableStatement(d, err) }, fn)
+}
+
+// retryIf calls fn until it succeeds, fails with an error that should not be retried or runs out of attempts
+func (m *Sql) retryIf(ctx context.Context, shouldRetry func(error) bool, fn func() error) error {
+	delay := time.Duration(m.RetryBackoff) * time.Second
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= m.RetryAttempts || !shouldRetry(err) {
+			if err != nil && attempt > 0 {
+				return fmt.Errorf("failed after %d attempts: %w", attempt+1, err)
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// retryable reports whether an error is transient, either at the network level or for the dialect
+func retryable(d dialect, err error) bool {
+	// the deadline or cancellation of the call is final
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr *net.OpError
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &netErr) {
+		return true
+	}
+
+	if d != nil {
+		c, ok := d.(retryClassifier)
+		return ok && c.Retryable(err)
+	}
+	for _, d := range dialects {
+		if c, ok := d.(retryClassifier); ok && c.Retryable(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryableStatement reports whether a statement failed without taking effect, which the driver guarantees for a bad
+// connection and the dialect for rolled back statements
+func retryableStatement(d dialect, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// drivers only return ErrBadConn when nothing was sent to the server
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	c, ok := d.(retryClassifier)
+	return ok && c.RolledBack(err)
+}
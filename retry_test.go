@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		d    dialect
+		err  error
+		want bool
+	}{
+		{name: "canceled", d: postgresDialect{}, err: context.Canceled, want: false},
+		{name: "deadline exceeded", d: postgresDialect{}, err: fmt.Errorf("error querying database: %w", context.DeadlineExceeded), want: false},
+		{name: "bad connection", d: postgresDialect{}, err: driver.ErrBadConn, want: true},
+		{name: "unexpected eof", d: postgresDialect{}, err: io.ErrUnexpectedEOF, want: true},
+		{name: "connection reset", d: mysqlDialect{}, err: fmt.Errorf("error executing statement: %w", syscall.ECONNRESET), want: true},
+		{name: "connection refused", d: nil, err: syscall.ECONNREFUSED, want: true},
+		{name: "network error", d: sqlserverDialect{}, err: &net.OpError{Op: "read", Err: errors.New("broken pipe")}, want: true},
+		{name: "other error", d: postgresDialect{}, err: errors.New("syntax error"), want: false},
+		{name: "postgres serialization failure", d: postgresDialect{}, err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "postgres deadlock", d: postgresDialect{}, err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "postgres shutting down", d: postgresDialect{}, err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "postgres connection exception", d: redshiftDialect{}, err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "postgres unique violation", d: postgresDialect{}, err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "postgres error for another dialect", d: mysqlDialect{}, err: &pgconn.PgError{Code: "40001"}, want: false},
+		{name: "postgres error while connecting", d: nil, err: &pgconn.PgError{Code: "57P03"}, want: true},
+		{name: "mysql deadlock", d: mysqlDialect{}, err: &mysql.MySQLError{Number: 1213}, want: true},
+		{name: "mysql server gone away", d: mariadbDialect{}, err: &mysql.MySQLError{Number: 2006}, want: true},
+		{name: "mysql invalid connection", d: mysqlDialect{}, err: mysql.ErrInvalidConn, want: true},
+		{name: "mysql duplicate entry", d: mysqlDialect{}, err: &mysql.MySQLError{Number: 1062}, want: false},
+		{name: "sqlserver deadlock", d: sqlserverDialect{}, err: mssql.Error{Number: 1205}, want: true},
+		{name: "azure sql unavailable", d: sqlserverDialect{}, err: mssql.Error{Number: 40613}, want: true},
+		{name: "sqlserver constraint violation", d: sqlserverDialect{}, err: mssql.Error{Number: 547}, want: false},
+		{name: "dialect without classifier", d: sqliteDialect{}, err: errors.New("database is locked"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.d, tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryableStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		d    dialect
+		err  error
+		want bool
+	}{
+		{name: "canceled", d: postgresDialect{}, err: context.Canceled, want: false},
+		{name: "bad connection", d: postgresDialect{}, err: fmt.Errorf("error executing statement: %w", driver.ErrBadConn), want: true},
+		// the statement may have run before the connection was lost
+		{name: "unexpected eof", d: postgresDialect{}, err: io.ErrUnexpectedEOF, want: false},
+		{name: "connection reset", d: mysqlDialect{}, err: syscall.ECONNRESET, want: false},
+		{name: "network error", d: sqlserverDialect{}, err: &net.OpError{Op: "read", Err: errors.New("broken pipe")}, want: false},
+		{name: "postgres serialization failure", d: postgresDialect{}, err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "postgres deadlock", d: yugabyteDialect{}, err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "postgres shutting down", d: postgresDialect{}, err: &pgconn.PgError{Code: "57P01"}, want: false},
+		{name: "postgres connection exception", d: postgresDialect{}, err: &pgconn.PgError{Code: "08006"}, want: false},
+		{name: "mysql deadlock", d: mysqlDialect{}, err: &mysql.MySQLError{Number: 1213}, want: true},
+		{name: "mysql lock wait timeout", d: tidbDialect{}, err: &mysql.MySQLError{Number: 1205}, want: true},
+		{name: "mysql lost connection", d: mysqlDialect{}, err: &mysql.MySQLError{Number: 2013}, want: false},
+		{name: "mysql invalid connection", d: mysqlDialect{}, err: mysql.ErrInvalidConn, want: false},
+		{name: "sqlserver deadlock", d: sqlserverDialect{}, err: mssql.Error{Number: 1205}, want: true},
+		{name: "azure sql unavailable", d: sqlserverDialect{}, err: mssql.Error{Number: 40613}, want: false},
+		{name: "dialect without classifier", d: sqliteDialect{}, err: errors.New("database is locked"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableStatement(tt.d, tt.err); got != tt.want {
+				t.Errorf("retryableStatement(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	m := &Sql{RetryAttempts: 2}
+
+	calls := 0
+	err := m.retryStatement(context.Background(), postgresDialect{}, func() error {
+		calls++
+		return &pgconn.PgError{Code: "40001"}
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("retried a serialization failure %d times with error %v, want 3 attempts and an error", calls, err)
+	}
+
+	calls = 0
+	err = m.retryStatement(context.Background(), postgresDialect{}, func() error {
+		calls++
+		return io.ErrUnexpectedEOF
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || calls != 1 {
+		t.Errorf("ran a statement %d times after a lost connection with error %v, want a single attempt", calls, err)
+	}
+
+	calls = 0
+	err = m.retry(context.Background(), postgresDialect{}, func() error {
+		if calls++; calls < 2 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("retried a lost connection %d times with error %v, want success on the second attempt", calls, err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Routine represents a function or stored procedure
+type Routine struct {
+	Name string
+	// Kind is FUNCTION or PROCEDURE, postgres also has AGGREGATE and WINDOW
+	Kind string
+	// Arguments is the argument signature, e.g. customer_id integer, since date
+	Arguments string
+	// ReturnType is empty for procedures that return nothing
+	ReturnType string
+	Language   string
+}
+
+// routineLister is implemented by dialects that can list the functions and procedures of a schema
+type routineLister interface {
+	// RoutinesQuery lists the name, kind, arguments, return type and language of the routines in a schema, ordered by
+	// name, overloaded routines have a row for each signature
+	RoutinesQuery(database, schema string) (string, error)
+}
+
+// List the functions and procedures of a schema with their signatures, return types and languages
+func (m *Sql) ListRoutines(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+) ([]*Routine, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(routineLister)
+	if !ok {
+		return nil, fmt.Errorf("listing routines is not supported for %s", d.Name())
+	}
+	query, err := l.RoutinesQuery(database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying routines: %w", err)
+	}
+	defer rows.Close()
+
+	routines := []*Routine{}
+	for rows.Next() {
+		routine := &Routine{}
+		// oracle returns NULL for routines without arguments
+		var arguments, returnType sql.NullString
+		if err := rows.Scan(&routine.Name, &routine.Kind, &arguments, &returnType, &routine.Language); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		routine.Arguments = arguments.String
+		routine.ReturnType = returnType.String
+		routines = append(routines, routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return routines, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RowCount represents the number of rows in a table
+type RowCount struct {
+	Table string
+	Rows  int
+	// Estimated is true when the count comes from the statistics of the database instead of COUNT(*)
+	Estimated bool
+}
+
+// rowEstimator is implemented by dialects that keep estimates of the number of rows in their tables
+type rowEstimator interface {
+	// RowEstimatesQuery lists the name and estimated row count of the tables in a schema, ordered by name
+	RowEstimatesQuery(database, schema string) (string, error)
+}
+
+// Count the rows of every table in a schema, estimates from the statistics of the database are fast but can be stale
+func (m *Sql) RowCounts(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+	// Count every row with COUNT(*) instead of using estimates, databases without estimates are always counted
+	// +optional
+	exact bool,
+) ([]*RowCount, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	if e, ok := d.(rowEstimator); ok && !exact {
+		query, err := e.RowEstimatesQuery(database, schema)
+		if err != nil {
+			return nil, err
+		}
+		return m.rowEstimates(ctx, db, query)
+	}
+
+	tables, err := m.ListTables(ctx, schema, "")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+
+	counts := make([]*RowCount, len(tables))
+	for i, table := range tables {
+		n, err := m.countRows(ctx, db, fmt.Sprintf("SELECT COUNT(*) FROM %s", qualifiedTable(d, schema, table)))
+		if err != nil {
+			return nil, fmt.Errorf("error counting rows of %s: %w", table, err)
+		}
+		counts[i] = &RowCount{Table: table, Rows: n}
+	}
+
+	return counts, nil
+}
+
+// rowEstimates reads the estimated row counts of the tables of a schema
+func (m *Sql) rowEstimates(ctx context.Context, db *sql.DB, query string) ([]*RowCount, error) {
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying row estimates: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []*RowCount{}
+	for rows.Next() {
+		count := &RowCount{Estimated: true}
+		if err := rows.Scan(&count.Table, &count.Rows); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// countRows runs a COUNT(*) query with its own statement timeout
+func (m *Sql) countRows(ctx context.Context, db *sql.DB, query string) (int, error) {
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	var n int
+	if err := db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// qualifiedTable quotes a table listed by ListTables, qualified with its schema unless the schema is the default of the
+// connection, public is only a schema of its own for postgres
+func qualifiedTable(d dialect, schema, table string) string {
+	switch {
+	case d.Engine() == "mysql" || d.Engine() == "sqlite":
+		return d.Quote(table)
+	case schema == "public" && d.Engine() != "postgres":
+		return d.Quote(table)
+	case d.Engine() == "oracle" || d.Engine() == "snowflake":
+		// unquoted names are stored in upper case, as ListTables compares them
+		return d.Quote(strings.ToUpper(schema)) + "." + d.Quote(table)
+	}
+
+	return d.Quote(schema) + "." + d.Quote(table)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaDescription represents the tables of a schema and their columns
+type SchemaDescription struct {
+	Name   string
+	Tables []*TableDescription
+}
+
+// TableDescription represents a table or view and its columns in order
+type TableDescription struct {
+	Name    string
+	Columns []*ColumnDescription
+}
+
+// ColumnDescription represents a column of a table
+type ColumnDescription struct {
+	Name       string
+	DataType   string
+	IsNullable bool
+	// Default is the default expression of the column, empty when it has none
+	Default string
+	// Position is the 1-based position of the column in its table
+	Position int
+}
+
+// Describe every table in a schema with its columns in a single query
+func (m *Sql) DescribeSchema(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+) (*SchemaDescription, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	query := d.SchemaQuery(database, schema)
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying schema: %w", err)
+	}
+	defer rows.Close()
+
+	description := &SchemaDescription{Name: schema, Tables: []*TableDescription{}}
+	var table *TableDescription
+	for rows.Next() {
+		var tableName, isNullable string
+		var columnDefault sql.NullString
+		column := &ColumnDescription{}
+		if err := rows.Scan(&tableName, &column.Name, &column.DataType, &isNullable, &columnDefault, &column.Position); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		column.IsNullable = isNullable == "YES"
+		column.Default = columnDefault.String
+
+		// rows are ordered by table, so a new name starts the next table
+		if table == nil || table.Name != tableName {
+			table = &TableDescription{Name: tableName}
+			description.Tables = append(description.Tables, table)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return description, nil
+}
+
+// Encode the schema description as JSON
+func (s *SchemaDescription) Json() (string, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding schema: %w", err)
+	}
+
+	return string(b), nil
+}
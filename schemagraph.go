@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const (
+	// graphvizImage is the container image graphviz is installed in to render schema graphs
+	graphvizImage = "alpine:3.21"
+	// graphvizPath is where a schema graph is written and rendered inside the graphviz container
+	graphvizPath = "/graph"
+)
+
+// Export a graphviz graph of the tables in a schema and their foreign key relationships, as DOT or rendered to svg or png
+func (m *Sql) SchemaGraph(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+	// Format of the graph, dot for the source or svg and png rendered with graphviz
+	// +default="dot"
+	format string,
+	// Gzip the file, adding .gz to its name
+	// +optional
+	compress bool,
+) (*dagger.File, error) {
+	format = strings.ToLower(format)
+	switch format {
+	case "dot", "svg", "png":
+	default:
+		return nil, fmt.Errorf("unsupported graph format %q, supported formats are dot, svg and png", format)
+	}
+
+	dot, err := m.schemaDot(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	if format == "dot" {
+		f, err := createResultFile("schema.dot", compress)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if _, err := io.WriteString(f, dot); err != nil {
+			return nil, fmt.Errorf("error writing schema graph: %w", err)
+		}
+		return f.file(ctx)
+	}
+
+	output := graphvizPath + "/schema." + format
+	render := dag.Container().From(graphvizImage).
+		// fonts let graphviz measure the labels of the tables
+		WithExec([]string{"apk", "add", "--no-cache", "graphviz", "font-dejavu"}).
+		WithNewFile(graphvizPath+"/schema.dot", dot).
+		WithExec([]string{"dot", "-T" + format, "-o", output, graphvizPath + "/schema.dot"})
+	// the rendered graph is only in the container, so it is compressed there
+	if compress {
+		render = render.WithExec([]string{"gzip", output})
+		output += ".gz"
+	}
+	file, err := render.File(output).Sync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering schema graph: %w", err)
+	}
+
+	return file, nil
+}
+
+// schemaDot returns a DOT graph with a node for each table that lists its columns, and an edge from the first column of
+// each foreign key to the column it references
+func (m *Sql) schemaDot(ctx context.Context, schema string) (string, error) {
+	description, err := m.DescribeSchema(ctx, schema)
+	if err != nil {
+		return "", err
+	}
+	keys, primaryKeys, err := m.schemaKeys(ctx, schema, description)
+	if err != nil {
+		return "", err
+	}
+
+	foreign := map[string]bool{}
+	for _, key := range keys {
+		for _, column := range key.Columns {
+			foreign[key.Table+"."+column] = true
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotID(schema))
+	b.WriteString("    graph [rankdir=LR];\n")
+	b.WriteString("    node [shape=plain, fontname=\"DejaVu Sans\", fontsize=10];\n")
+	b.WriteString("    edge [arrowhead=normal, fontname=\"DejaVu Sans\", fontsize=8];\n")
+	for _, table := range description.Tables {
+		fmt.Fprintf(&b, "    %s [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\" cellpadding=\"4\">\n", dotID(table.Name))
+		fmt.Fprintf(&b, "        <tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", html.EscapeString(table.Name))
+		for _, column := range table.Columns {
+			label := html.EscapeString(column.Name + " " + column.DataType)
+			if primaryKeys[table.Name][column.Name] {
+				label = "<u>" + label + "</u>"
+			}
+			if foreign[table.Name+"."+column.Name] {
+				label = "<i>" + label + "</i>"
+			}
+			fmt.Fprintf(&b, "        <tr><td port=%q align=\"left\">%s</td></tr>\n", html.EscapeString(column.Name), label)
+		}
+		b.WriteString("    </table>>];\n")
+	}
+
+	for _, key := range keys {
+		head := dotID(key.ReferencedTable)
+		// SQLite leaves out the referenced columns of a key that references the primary key
+		if key.ReferencedColumns[0] != "" {
+			head += ":" + dotID(key.ReferencedColumns[0])
+		}
+		fmt.Fprintf(&b, "    %s:%s -> %s [label=%s];\n", dotID(key.Table), dotID(key.Columns[0]), head, dotID(key.Name))
+	}
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+// dotID quotes a name as a DOT identifier
+func dotID(name string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), `"`, `\"`) + `"`
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SchemaDetails represents a schema the connection can see
+type SchemaDetails struct {
+	Name string
+	// Owner is the user or role that owns the schema, empty for databases without schema owners
+	Owner string
+}
+
+// schemaLister is implemented by dialects that can list their schemas
+type schemaLister interface {
+	// SchemasQuery lists the name and owner of the schemas outside the system schemas, ordered by name
+	SchemasQuery(database string) (string, error)
+}
+
+// List the schemas the connection can see with their owners, leaving out system schemas
+func (m *Sql) ListSchemas(ctx context.Context) ([]*SchemaDetails, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(schemaLister)
+	if !ok {
+		return nil, fmt.Errorf("listing schemas is not supported for %s", d.Name())
+	}
+	query, err := l.SchemasQuery(database)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []*SchemaDetails{}
+	for rows.Next() {
+		schema := &SchemaDetails{}
+		var owner sql.NullString
+		if err := rows.Scan(&schema.Name, &owner); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		schema.Owner = owner.String
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return schemas, nil
+}
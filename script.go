@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Execute the statements of a SQL script in order, stopping at the first failure
+func (m *Sql) RunScript(
+	ctx context.Context,
+	file *dagger.File,
+	// Show the plan of every statement instead of running them
+	// +optional
+	dryRun bool,
+) (*ExecResult, error) {
+	if m.Duckdb {
+		return nil, fmt.Errorf("scripts are not supported for duckdb")
+	}
+
+	script, err := file.Contents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading script: %w", err)
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+	statements := splitStatements(script, d.Engine())
+
+	if dryRun {
+		plan, err := m.dryRun(ctx, statements)
+		if err != nil {
+			return nil, err
+		}
+		return &ExecResult{Plan: plan}, nil
+	}
+
+	return m.runStatements(ctx, db, d, statements)
+}
+
+// ScriptResult represents the outcome of a SQL file run by RunDirectory
+type ScriptResult struct {
+	File         string
+	RowsAffected int
+	// Error is empty when every statement in the file succeeded
+	Error string
+}
+
+// Execute every matching SQL file in a directory in lexicographic order, stopping at the first failure unless continueOnError is set
+func (m *Sql) RunDirectory(
+	ctx context.Context,
+	dir *dagger.Directory,
+	// Pattern of the files to run
+	// +default="*.sql"
+	glob string,
+	// Run the remaining files after one fails and report the failure in its result
+	// +optional
+	continueOnError bool,
+) ([]*ScriptResult, error) {
+	if m.Duckdb {
+		return nil, fmt.Errorf("scripts are not supported for duckdb")
+	}
+
+	files, err := dir.Glob(ctx, glob)
+	if err != nil {
+		return nil, fmt.Errorf("error listing files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", glob)
+	}
+	sort.Strings(files)
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	var results []*ScriptResult
+	for _, name := range files {
+		script, err := dir.File(name).Contents(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+
+		result, err := m.runStatements(ctx, db, d, splitStatements(script, d.Engine()))
+		if err != nil {
+			if !continueOnError {
+				return nil, fmt.Errorf("error running %s after %d successful files: %w", name, len(results), err)
+			}
+			results = append(results, &ScriptResult{File: name, Error: err.Error()})
+			continue
+		}
+
+		results = append(results, &ScriptResult{File: name, RowsAffected: result.RowsAffected})
+	}
+
+	return results, nil
+}
+
+// delimiterDirective matches the DELIMITER command of the mysql client, which changes the statement delimiter of a script
+var delimiterDirective = regexp.MustCompile(`^[ \t]*(?i:delimiter)[ \t]+(\S+)[ \t]*(\r?\n|$)`)
+
+// splitStatements splits a script on its delimiter outside of string literals, quoted identifiers, dollar-quoted bodies
+// and comments, following the syntax of the engine: mysql has backslash escapes, backticks, # comments and DELIMITER
+// directives, while postgres has dollar quoting, escape strings such as E'\n' and nested block comments
+func splitStatements(script string, engine string) []string {
+	mysqlSyntax := engine == "mysql"
+	delimiter := ";"
+
+	var statements []string
+	start := 0
+	for i := 0; i < len(script); {
+		if mysqlSyntax && (i == 0 || script[i-1] == '\n') && stripComments(script[start:i]) == "" {
+			if match := delimiterDirective.FindStringSubmatch(script[i:]); match != nil {
+				delimiter = match[1]
+				i += len(match[0])
+				start = i
+				continue
+			}
+		}
+
+		if strings.HasPrefix(script[i:], delimiter) {
+			statements = appendStatement(statements, script[start:i])
+			i += len(delimiter)
+			start = i
+			continue
+		}
+
+		i += tokenLength(script[i:], mysqlSyntax)
+	}
+
+	return appendStatement(statements, script[start:])
+}
+
+// tokenLength returns the length of the literal, quoted identifier or comment at the start of s, or 1 for any other character
+func tokenLength(s string, mysqlSyntax bool) int {
+	switch c := s[0]; {
+	case c == '\'' || c == '"':
+		return quotedLength(s, c, mysqlSyntax)
+	case c == '`' && mysqlSyntax:
+		return quotedLength(s, c, false)
+	case (c == 'E' || c == 'e') && !mysqlSyntax && strings.HasPrefix(s[1:], "'"):
+		return 1 + quotedLength(s[1:], '\'', true)
+	case c == '-' && strings.HasPrefix(s, "--"):
+		// mysql only starts a comment when whitespace follows the dashes
+		if mysqlSyntax && len(s) > 2 && !strings.ContainsRune(" \t\r\n", rune(s[2])) {
+			return 1
+		}
+		return lineLength(s)
+	case c == '#' && mysqlSyntax:
+		return lineLength(s)
+	case c == '/' && strings.HasPrefix(s, "/*"):
+		return commentLength(s, !mysqlSyntax)
+	case c == '$' && !mysqlSyntax:
+		// a dollar quote is $$ or $tag$, where the tag is an identifier
+		tag := dollarTag(s)
+		if tag == "" {
+			return 1
+		}
+		if end := strings.Index(s[len(tag):], tag); end >= 0 {
+			return len(tag) + end + len(tag)
+		}
+		return len(s)
+	}
+
+	return 1
+}
+
+// quotedLength returns the length of the quoted text at the start of s, a doubled quote is read as two adjacent literals
+func quotedLength(s string, quote byte, backslashEscapes bool) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+
+	return len(s)
+}
+
+// lineLength returns the length of s up to the end of its first line
+func lineLength(s string) int {
+	if end := strings.IndexByte(s, '\n'); end >= 0 {
+		return end
+	}
+
+	return len(s)
+}
+
+// commentLength returns the length of the block comment at the start of s, postgres allows nesting them
+func commentLength(s string, nested bool) int {
+	depth := 0
+	for i := 0; i < len(s)-1; i++ {
+		switch {
+		case s[i] == '/' && s[i+1] == '*' && (nested || depth == 0):
+			depth++
+			i++
+		case s[i] == '*' && s[i+1] == '/':
+			if depth--; depth == 0 {
+				return i + 2
+			}
+			i++
+		}
+	}
+
+	return len(s)
+}
+
+// dollarTag returns the dollar quote opening s, e.g. $body$, or an empty string
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
+
+// appendStatement appends a statement unless it only contains whitespace and comments
+func appendStatement(statements []string, statement string) []string {
+	statement = strings.TrimSpace(statement)
+	if stripComments(statement) == "" {
+		return statements
+	}
+
+	return append(statements, statement)
+}
+
+// stripComments removes leading comments and whitespace
+func stripComments(statement string) string {
+	for {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case strings.HasPrefix(statement, "--"), strings.HasPrefix(statement, "#"):
+			_, rest, _ := strings.Cut(statement, "\n")
+			statement = rest
+		case strings.HasPrefix(statement, "/*"):
+			_, rest, ok := strings.Cut(statement, "*/")
+			if !ok {
+				return ""
+			}
+			statement = rest
+		default:
+			return statement
+		}
+	}
+}
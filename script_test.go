@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		script string
+		want   []string
+	}{
+		{name: "empty", engine: "postgres", script: "", want: nil},
+		{name: "only comments", engine: "postgres", script: "-- nothing\n/* to run */;", want: nil},
+		{name: "single without delimiter", engine: "postgres", script: "SELECT 1", want: []string{"SELECT 1"}},
+		{name: "trailing delimiter", engine: "postgres", script: "SELECT 1;\nSELECT 2;\n", want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "empty statements", engine: "postgres", script: "SELECT 1;;  ;SELECT 2", want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "delimiter in literal", engine: "postgres", script: "SELECT ';'; SELECT 2", want: []string{"SELECT ';'", "SELECT 2"}},
+		{name: "doubled quote", engine: "postgres", script: "SELECT 'it''s;'; SELECT 2", want: []string{"SELECT 'it''s;'", "SELECT 2"}},
+		{name: "delimiter in quoted identifier", engine: "postgres", script: `SELECT 1 AS "a;b"; SELECT 2`, want: []string{`SELECT 1 AS "a;b"`, "SELECT 2"}},
+		{name: "delimiter in line comment", engine: "postgres", script: "SELECT 1 -- a;b\n; SELECT 2", want: []string{"SELECT 1 -- a;b", "SELECT 2"}},
+		{name: "delimiter in block comment", engine: "postgres", script: "SELECT /* a;b */ 1; SELECT 2", want: []string{"SELECT /* a;b */ 1", "SELECT 2"}},
+		{name: "nested block comment", engine: "postgres", script: "SELECT /* a /* b; */ c; */ 1; SELECT 2", want: []string{"SELECT /* a /* b; */ c; */ 1", "SELECT 2"}},
+		{
+			name:   "dollar quoted body",
+			engine: "postgres",
+			script: "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; SELECT f()",
+			want:   []string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", "SELECT f()"},
+		},
+		{
+			name:   "tagged dollar quote",
+			engine: "postgres",
+			script: "DO $body$ BEGIN PERFORM 1; END $body$; SELECT 2",
+			want:   []string{"DO $body$ BEGIN PERFORM 1; END $body$", "SELECT 2"},
+		},
+		{name: "positional parameter", engine: "postgres", script: "SELECT $1; SELECT $2", want: []string{"SELECT $1", "SELECT $2"}},
+		{name: "escape string", engine: "postgres", script: `SELECT E'\';'; SELECT 2`, want: []string{`SELECT E'\';'`, "SELECT 2"}},
+		{name: "backslash in postgres literal", engine: "postgres", script: `SELECT '\'; SELECT 2`, want: []string{`SELECT '\'`, "SELECT 2"}},
+		{name: "mysql backslash escape", engine: "mysql", script: `SELECT '\';'; SELECT 2`, want: []string{`SELECT '\';'`, "SELECT 2"}},
+		{name: "mysql backtick", engine: "mysql", script: "SELECT 1 AS `a;b`; SELECT 2", want: []string{"SELECT 1 AS `a;b`", "SELECT 2"}},
+		{name: "mysql hash comment", engine: "mysql", script: "SELECT 1 # a;b\n; SELECT 2", want: []string{"SELECT 1 # a;b", "SELECT 2"}},
+		{name: "mysql dashes without space", engine: "mysql", script: "SELECT 1--1; SELECT 2", want: []string{"SELECT 1--1", "SELECT 2"}},
+		{
+			name:   "mysql delimiter directive",
+			engine: "mysql",
+			script: "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END //\nDELIMITER ;\nCALL p();",
+			want:   []string{"CREATE PROCEDURE p() BEGIN SELECT 1; END", "CALL p()"},
+		},
+		{name: "delimiter directive outside mysql", engine: "postgres", script: "DELIMITER //\nSELECT 1;", want: []string{"DELIMITER //\nSELECT 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script, tt.engine); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Sequence represents a sequence and how much of its range it has used
+type Sequence struct {
+	Name     string
+	DataType string
+	// CurrentValue is the last value the sequence returned, 0 when it has not been used
+	CurrentValue int
+	Increment    int
+	MinValue     int
+	MaxValue     int
+	// OwnedBy is the table.column the sequence belongs to, empty when it belongs to none
+	OwnedBy string
+	// PercentUsed is how much of the range between the min and max value the sequence has used
+	PercentUsed float64
+}
+
+// sequenceLister is implemented by dialects that can list the sequences of a schema
+type sequenceLister interface {
+	// SequencesQuery lists the name, data type, current value (NULL when unused), increment, min value, max value and
+	// owning column (NULL or empty when none) of the sequences in a schema, ordered by name
+	SequencesQuery(database, schema string) (string, error)
+}
+
+// List the sequences of a schema with their current values and how much of their range they have used
+func (m *Sql) ListSequences(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+	// Only list sequences that have used at least this percentage of their range, e.g. 80 to find the ones running out
+	// +optional
+	minPercentUsed int,
+) ([]*Sequence, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(sequenceLister)
+	if !ok {
+		return nil, fmt.Errorf("listing sequences is not supported for %s", d.Name())
+	}
+	query, err := l.SequencesQuery(database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := []*Sequence{}
+	for rows.Next() {
+		sequence := &Sequence{}
+		var current sql.NullInt64
+		var ownedBy sql.NullString
+		if err := rows.Scan(&sequence.Name, &sequence.DataType, &current, &sequence.Increment, &sequence.MinValue, &sequence.MaxValue, &ownedBy); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		sequence.OwnedBy = ownedBy.String
+		if current.Valid {
+			sequence.CurrentValue = int(current.Int64)
+			sequence.PercentUsed = percentUsed(current.Int64, int64(sequence.Increment), int64(sequence.MinValue), int64(sequence.MaxValue))
+		}
+
+		if sequence.PercentUsed >= float64(minPercentUsed) {
+			sequences = append(sequences, sequence)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return sequences, nil
+}
+
+// percentUsed returns how far a sequence is through its range, descending sequences count down from the max value
+func percentUsed(current, increment, minValue, maxValue int64) float64 {
+	// floats keep the range of a bigint sequence from overflowing
+	span := float64(maxValue) - float64(minValue)
+	if span <= 0 {
+		return 100
+	}
+
+	used := float64(current) - float64(minValue)
+	if increment < 0 {
+		used = float64(maxValue) - float64(current)
+	}
+
+	return used / span * 100
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Enforce read-only transactions for every connection (postgres, mysql and sqlite only)
+func (m *Sql) ReadOnly() *Sql {
+	m.ReadOnlySession = true
+	return m
+}
+
+// Configure the session of every connection, empty values keep the server defaults (postgres and mysql only)
+func (m *Sql) WithSession(
+	// Comma-separated schemas to resolve unqualified names in, e.g. app,public (a single database for mysql)
+	// +optional
+	searchPath string,
+	// Time zone of the session, e.g. UTC
+	// +optional
+	timezone string,
+	// Role to assume for the session
+	// +optional
+	role string,
+) *Sql {
+	m.SessionSearchPath = searchPath
+	m.SessionTimezone = timezone
+	m.SessionRole = role
+	return m
+}
+
+// hasSession reports whether any session options are configured
+func (m *Sql) hasSession() bool {
+	return m.SessionSearchPath != "" || m.SessionTimezone != "" || m.SessionRole != ""
+}
+
+// quoteLiteral quotes a string literal by doubling its quotes as in standard SQL, use the QuoteLiteral of the dialect
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sessionConnector runs statements on every new connection before database/sql uses it
+type sessionConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("driver does not support session statements")
+	}
+
+	for _, statement := range c.statements {
+		if _, err := execer.ExecContext(ctx, statement, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error configuring session: %w", err)
+		}
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaSnapshot represents the structure of the tables of a schema, the parts a database cannot list are empty
+type SchemaSnapshot struct {
+	Schema string
+	Tables []*TableSnapshot
+}
+
+// TableSnapshot represents the structure of a table
+type TableSnapshot struct {
+	Name        string
+	Columns     []*ColumnDescription
+	PrimaryKey  []string
+	Indexes     []*IndexDetails
+	Constraints []*Constraint
+	ForeignKeys []*ForeignKey
+}
+
+// Save the tables of a schema with their columns, keys, indexes and constraints as JSON, a baseline for check-drift
+func (m *Sql) SnapshotSchema(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+	// Gzip the file, adding .gz to its name, check-drift reads uncompressed snapshots
+	// +optional
+	compress bool,
+) (*dagger.File, error) {
+	snapshot, err := m.snapshotSchema(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding schema snapshot: %w", err)
+	}
+
+	f, err := createResultFile("schema.json", compress)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return nil, fmt.Errorf("error writing schema snapshot: %w", err)
+	}
+
+	return f.file(ctx)
+}
+
+// Compare the live schema with a snapshot from snapshot-schema and fail with the differences when it has drifted
+func (m *Sql) CheckDrift(ctx context.Context, baseline *dagger.File) (string, error) {
+	contents, err := baseline.Contents(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error reading baseline: %w", err)
+	}
+
+	var expected SchemaSnapshot
+	if err := json.Unmarshal([]byte(contents), &expected); err != nil {
+		return "", fmt.Errorf("error decoding baseline: %w", err)
+	}
+	if expected.Schema == "" {
+		return "", fmt.Errorf("baseline is not a schema snapshot")
+	}
+
+	live, err := m.snapshotSchema(ctx, expected.Schema)
+	if err != nil {
+		return "", err
+	}
+
+	// changes turn the baseline into the live schema, so added objects are only in the database
+	diff := diffSnapshots(&expected, live)
+	if len(diff.Changes) > 0 {
+		return "", fmt.Errorf("schema %s has drifted from the baseline:\n%s", expected.Schema, diff.Summary)
+	}
+
+	return diff.Summary, nil
+}
+
+// snapshotSchema reads the tables of a schema with their columns, keys, indexes and constraints
+func (m *Sql) snapshotSchema(ctx context.Context, schema string) (*SchemaSnapshot, error) {
+	description, err := m.DescribeSchema(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	_, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	// redshift implements some of the optional queries only to explain why it cannot run them
+	_, primaryKeys := d.(primaryKeyLister)
+	indexes := false
+	if l, ok := d.(indexLister); ok {
+		_, err := l.IndexesQuery(database, "")
+		indexes = err == nil
+	}
+	constraints := false
+	if l, ok := d.(constraintLister); ok {
+		_, err := l.ConstraintsQuery(database, "")
+		constraints = err == nil
+	}
+	foreignKeys := map[string][]*ForeignKey{}
+	if l, ok := d.(foreignKeyLister); ok {
+		if _, err := l.ForeignKeysQuery(database, schema); err == nil {
+			keys, err := m.ListForeignKeys(ctx, schema)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range keys {
+				foreignKeys[key.Table] = append(foreignKeys[key.Table], key)
+			}
+		}
+	}
+
+	snapshot := &SchemaSnapshot{Schema: schema, Tables: make([]*TableSnapshot, len(description.Tables))}
+	for i, table := range description.Tables {
+		t := &TableSnapshot{
+			Name:        table.Name,
+			Columns:     table.Columns,
+			PrimaryKey:  []string{},
+			Indexes:     []*IndexDetails{},
+			Constraints: []*Constraint{},
+			ForeignKeys: []*ForeignKey{},
+		}
+		name := keyTable(d, schema, table.Name)
+		if primaryKeys {
+			if t.PrimaryKey, err = m.PrimaryKey(ctx, name); err != nil {
+				return nil, err
+			}
+		}
+		if indexes {
+			if t.Indexes, err = m.ListIndexes(ctx, name); err != nil {
+				return nil, err
+			}
+		}
+		if constraints {
+			if t.Constraints, err = m.ListConstraints(ctx, name); err != nil {
+				return nil, err
+			}
+		}
+		if keys, ok := foreignKeys[table.Name]; ok {
+			t.ForeignKeys = keys
+		}
+		snapshot.Tables[i] = t
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	_ "github.com/snowflakedb/gosnowflake"
+)
+
+type snowflakeDialect struct{}
+
+func (snowflakeDialect) Name() string   { return "snowflake" }
+func (snowflakeDialect) Engine() string { return "snowflake" }
+
+func (snowflakeDialect) Detect(conn string) bool { return strings.HasPrefix(conn, "snowflake://") }
+
+func (snowflakeDialect) Open(_ context.Context, _ *Sql, dsn string) (*sql.DB, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error parsing connection string: %w", err)
+	}
+
+	// the snowflake driver expects the DSN without a scheme
+	db, err := sql.Open("snowflake", dsn[len("snowflake://"):])
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	// the path is database[/schema]
+	return db, strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)[0], nil
+}
+
+// BuildDSN uses the host as the snowflake account identifier
+func (snowflakeDialect) BuildDSN(host string, _ int, user, password, database string) string {
+	u := url.URL{Scheme: "snowflake", User: userInfo(user, password), Host: host, Path: "/" + database}
+	return u.String()
+}
+
+func (snowflakeDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	if analyze {
+		return "", fmt.Errorf("snowflake does not support explain analyze, see the query profile instead")
+	}
+
+	switch format {
+	case "text", "json":
+		return fmt.Sprintf("EXPLAIN USING %s %s", strings.ToUpper(format), statement), nil
+	}
+
+	return "", fmt.Errorf("unsupported explain format for snowflake: %s", format)
+}
+
+func (snowflakeDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// QuoteLiteral escapes backslashes, which snowflake reads as escapes in string literals
+func (snowflakeDialect) QuoteLiteral(value string) string {
+	return quoteLiteral(strings.ReplaceAll(value, `\`, `\\`))
+}
+
+func (snowflakeDialect) VersionQuery() string { return "SELECT CURRENT_VERSION()" }
+
+func (d snowflakeDialect) TablesQuery(database, schema, _ string) string {
+	// unquoted identifiers are stored in upper case, so names are compared in upper case
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = UPPER(%s) AND table_catalog = UPPER(%s) AND table_type = 'BASE TABLE'", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d snowflakeDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = UPPER(%s) AND table_catalog = UPPER(%s) ORDER BY ordinal_position", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d snowflakeDialect) ColumnDetailsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name, data_type, is_nullable, column_default, character_maximum_length, numeric_precision, numeric_scale, is_identity, comment, ordinal_position FROM information_schema.columns WHERE table_name = UPPER(%s) AND table_schema = CURRENT_SCHEMA() AND table_catalog = UPPER(%s) ORDER BY ordinal_position", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d snowflakeDialect) SchemaQuery(database, schema string) string {
+	return fmt.Sprintf("SELECT table_name, column_name, data_type, is_nullable, column_default, ordinal_position FROM information_schema.columns WHERE table_schema = UPPER(%s) AND table_catalog = UPPER(%s) ORDER BY table_name, ordinal_position", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d snowflakeDialect) ViewsQuery(database, schema string) (string, error) {
+	return fmt.Sprintf("SELECT table_name, view_definition FROM information_schema.views WHERE table_schema = UPPER(%s) AND table_catalog = UPPER(%s) ORDER BY table_name", d.QuoteLiteral(schema), d.QuoteLiteral(database)), nil
+}
+
+func (d snowflakeDialect) RoutinesQuery(database, schema string) (string, error) {
+	// argument signatures are wrapped in parentheses, e.g. (ID NUMBER)
+	return fmt.Sprintf(`SELECT function_name, 'FUNCTION', SUBSTR(argument_signature, 2, LENGTH(argument_signature) - 2), data_type, function_language FROM information_schema.functions WHERE function_schema = UPPER(%[1]s) AND function_catalog = UPPER(%[2]s)
+UNION ALL
+SELECT procedure_name, 'PROCEDURE', SUBSTR(argument_signature, 2, LENGTH(argument_signature) - 2), data_type, procedure_language FROM information_schema.procedures WHERE procedure_schema = UPPER(%[1]s) AND procedure_catalog = UPPER(%[2]s)
+ORDER BY 1`, d.QuoteLiteral(schema), d.QuoteLiteral(database)), nil
+}
+
+func (d snowflakeDialect) SchemasQuery(database string) (string, error) {
+	return fmt.Sprintf("SELECT schema_name, schema_owner FROM information_schema.schemata WHERE catalog_name = UPPER(%s) AND schema_name <> 'INFORMATION_SCHEMA' ORDER BY schema_name", d.QuoteLiteral(database)), nil
+}
+
+func (snowflakeDialect) DatabasesQuery() (string, error) {
+	return "SELECT database_name, database_owner FROM information_schema.databases ORDER BY database_name", nil
+}
+
+func (d snowflakeDialect) TableDDLQuery(_, table string) (string, error) {
+	return fmt.Sprintf("SELECT GET_DDL('TABLE', %s)", d.QuoteLiteral(table)), nil
+}
+
+func (d snowflakeDialect) RowEstimatesQuery(database, schema string) (string, error) {
+	return fmt.Sprintf("SELECT table_name, row_count FROM information_schema.tables WHERE table_schema = UPPER(%s) AND table_catalog = UPPER(%s) AND table_type = 'BASE TABLE' ORDER BY table_name", d.QuoteLiteral(schema), d.QuoteLiteral(database)), nil
+}
+
+func (d snowflakeDialect) TableSizesQuery(database, schema string) (string, error) {
+	// Snowflake has micro-partitions instead of indexes
+	return fmt.Sprintf("SELECT table_name, COALESCE(bytes, 0), 0, 0, COALESCE(bytes, 0) FROM information_schema.tables WHERE table_schema = UPPER(%s) AND table_catalog = UPPER(%s) AND table_type = 'BASE TABLE' ORDER BY 5 DESC, table_name", d.QuoteLiteral(schema), d.QuoteLiteral(database)), nil
+}
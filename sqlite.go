@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqlitePath is where a SQLite database file is written inside the module workdir
+const sqlitePath = "database.sqlite"
+
+// Use a SQLite database file instead of a connection string
+func (m *Sql) WithSqlite(file *dagger.File) *Sql {
+	m.Sqlite = file
+	return m
+}
+
+// Execute a statement against the SQLite database file and keep the changes
+func (m *Sql) WithSqliteStatement(ctx context.Context, statement string) (*Sql, error) {
+	if m.Sqlite == nil {
+		return nil, fmt.Errorf("no SQLite database file provided, use with-sqlite first")
+	}
+
+	if err := m.checkPolicy("sqlite", statement); err != nil {
+		return nil, err
+	}
+
+	db, _, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	execCtx, cancel := m.statementContext(ctx)
+	defer cancel()
+	if _, err := db.ExecContext(execCtx, statement); err != nil {
+		return nil, fmt.Errorf("error executing statement: %w", err)
+	}
+
+	// close the connection so every change is written to the file
+	if err := m.Close(); err != nil {
+		return nil, fmt.Errorf("error closing database connection: %w", err)
+	}
+
+	file, err := dag.CurrentModule().WorkdirFile(sqlitePath).Sync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SQLite database file: %w", err)
+	}
+	m.Sqlite = file
+
+	return m, nil
+}
+
+// Return the SQLite database file, including any changes made with WithSqliteStatement
+func (m *Sql) SqliteFile() (*dagger.File, error) {
+	if m.Sqlite == nil {
+		return nil, fmt.Errorf("no SQLite database file provided, use with-sqlite first")
+	}
+
+	return m.Sqlite, nil
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string   { return "sqlite" }
+func (sqliteDialect) Engine() string { return "sqlite" }
+
+func (sqliteDialect) Detect(conn string) bool {
+	return strings.HasPrefix(conn, "sqlite://") || strings.HasPrefix(conn, "file:")
+}
+
+func (sqliteDialect) Open(ctx context.Context, m *Sql, dsn string) (*sql.DB, string, error) {
+	if m.Sqlite != nil {
+		if _, err := m.Sqlite.Export(ctx, sqlitePath); err != nil {
+			return nil, "", fmt.Errorf("error exporting SQLite database file: %w", err)
+		}
+		dsn = sqlitePath
+	}
+	if strings.HasPrefix(strings.ToLower(dsn), "sqlite://") {
+		dsn = dsn[len("sqlite://"):]
+	}
+	if m.ReadOnlySession {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_pragma=query_only(1)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	return db, "main", nil
+}
+
+func (sqliteDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	if analyze || format != "text" {
+		return "", fmt.Errorf("sqlite only supports explaining text plans without analyze")
+	}
+
+	return "EXPLAIN QUERY PLAN " + statement, nil
+}
+
+func (sqliteDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) QuoteLiteral(value string) string { return quoteLiteral(value) }
+
+func (sqliteDialect) VersionQuery() string { return "SELECT sqlite_version()" }
+
+func (sqliteDialect) TablesQuery(_, _, _ string) string {
+	return "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+}
+
+func (d sqliteDialect) ColumnsQuery(_, table string) string {
+	return fmt.Sprintf("SELECT name FROM pragma_table_info(%s)", d.QuoteLiteral(table))
+}
+
+func (d sqliteDialect) ColumnDetailsQuery(_, table string) string {
+	// SQLite does not enforce the length of a type such as varchar(20), so there is no maximum length or precision,
+	// a single INTEGER PRIMARY KEY column is an alias of the rowid and is assigned automatically
+	return fmt.Sprintf(`SELECT name, type, CASE WHEN "notnull" = 0 THEN 'YES' ELSE 'NO' END, dflt_value, NULL, NULL, NULL,
+CASE WHEN pk = 1 AND UPPER(type) = 'INTEGER' AND (SELECT COUNT(*) FROM pragma_table_info(%[1]s) WHERE pk > 0) = 1 THEN 'YES' ELSE 'NO' END, NULL, cid + 1
+FROM pragma_table_info(%[1]s) ORDER BY cid`, d.QuoteLiteral(table))
+}
+
+func (sqliteDialect) SchemaQuery(_, _ string) string {
+	return `SELECT m.name, p.name, p.type, CASE WHEN p."notnull" = 0 THEN 'YES' ELSE 'NO' END, p.dflt_value, p.cid + 1 FROM sqlite_master m JOIN pragma_table_info(m.name) p WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%' ORDER BY m.name, p.cid`
+}
+
+func (d sqliteDialect) IndexesQuery(_, table string) (string, error) {
+	// every SQLite index is a b-tree
+	return fmt.Sprintf(`SELECT il.name, ii.name, CASE WHEN il."unique" = 1 THEN 'YES' ELSE 'NO' END, 'btree' FROM pragma_index_list(%s) il JOIN pragma_index_info(il.name) ii ORDER BY il.name, ii.seqno`, d.QuoteLiteral(table)), nil
+}
+
+func (sqliteDialect) ForeignKeysQuery(_, _ string) (string, error) {
+	// SQLite foreign keys have no names, so they are named after their table and id
+	return `SELECT 'fk_' || m.name || '_' || f.id, m.name, f."from", f."table", f."to", f.on_delete, f.on_update FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) f WHERE m.type = 'table' ORDER BY m.name, f.id, f.seq`, nil
+}
+
+func (d sqliteDialect) PrimaryKeyQuery(_, table string) (string, error) {
+	// pk is the 1-based position of a column in the primary key, 0 for other columns
+	return fmt.Sprintf("SELECT name FROM pragma_table_info(%s) WHERE pk > 0 ORDER BY pk", d.QuoteLiteral(table)), nil
+}
+
+func (d sqliteDialect) ConstraintsQuery(_, table string) (string, error) {
+	// SQLite keeps check constraints only in the CREATE TABLE statement, so only unique constraints are listed
+	return fmt.Sprintf(`SELECT il.name, 'UNIQUE', ii.name, NULL FROM pragma_index_list(%s) il JOIN pragma_index_info(il.name) ii WHERE il.origin = 'u' ORDER BY il.name, ii.seqno`, d.QuoteLiteral(table)), nil
+}
+
+func (sqliteDialect) ViewsQuery(_, _ string) (string, error) {
+	return "SELECT name, sql FROM sqlite_master WHERE type = 'view' ORDER BY name", nil
+}
+
+func (d sqliteDialect) TriggersQuery(_, table string) (string, error) {
+	// the timing and event are only in the CREATE TRIGGER statement, before the ON of its table, the timing is BEFORE
+	// when it is left out
+	return fmt.Sprintf(`WITH triggers AS (
+	SELECT name, sql, replace(replace(replace(upper(sql), char(13), ' '), char(10), ' '), char(9), ' ') AS flat
+	FROM sqlite_master WHERE type = 'trigger' AND tbl_name = %s
+), headers AS (
+	SELECT name, sql, substr(flat, 1, instr(flat, ' ON ')) AS header FROM triggers
+)
+SELECT name,
+	CASE WHEN header LIKE '%% INSTEAD OF %%' THEN 'INSTEAD OF' WHEN header LIKE '%% AFTER %%' THEN 'AFTER' ELSE 'BEFORE' END,
+	CASE WHEN header LIKE '%% INSERT %%' THEN 'INSERT' WHEN header LIKE '%% DELETE %%' THEN 'DELETE' ELSE 'UPDATE' END,
+	sql
+FROM headers ORDER BY name`, d.QuoteLiteral(table)), nil
+}
+
+func (sqliteDialect) SchemasQuery(_ string) (string, error) {
+	// the schemas of SQLite are its main, temp and attached databases
+	return "SELECT name, NULL FROM pragma_database_list ORDER BY seq", nil
+}
+
+func (d sqliteDialect) TableDDLQuery(_, table string) (string, error) {
+	// SQLite keeps the statements as they were written, the indexes of constraints have none
+	return fmt.Sprintf("SELECT sql FROM sqlite_master WHERE tbl_name = %s AND type IN ('table', 'index') AND sql IS NOT NULL ORDER BY type <> 'table', name", d.QuoteLiteral(table)), nil
+}
+
+func (sqliteDialect) TableSizesQuery(_, _ string) (string, error) {
+	// dbstat has the pages of every table and index, SQLite stores large values in overflow pages of the table
+	return `SELECT m.tbl_name, SUM(CASE WHEN m.type = 'table' THEN d.pgsize ELSE 0 END), SUM(CASE WHEN m.type = 'index' THEN d.pgsize ELSE 0 END), 0, SUM(d.pgsize) FROM dbstat d JOIN sqlite_master m ON m.name = d.name WHERE m.type IN ('table', 'index') GROUP BY m.tbl_name ORDER BY 5 DESC, m.tbl_name`, nil
+}
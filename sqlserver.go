@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+type sqlserverDialect struct{}
+
+func (sqlserverDialect) Name() string   { return "sqlserver" }
+func (sqlserverDialect) Engine() string { return "sqlserver" }
+
+func (sqlserverDialect) Detect(conn string) bool { return strings.HasPrefix(conn, "sqlserver://") }
+
+func (sqlserverDialect) Open(_ context.Context, _ *Sql, dsn string) (*sql.DB, string, error) {
+	db, err := sql.Open("sqlserver", dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		db.Close()
+		return nil, "", fmt.Errorf("error parsing connection string: %w", err)
+	}
+
+	return db, u.Query().Get("database"), nil
+}
+
+// Retryable matches deadlocks, lock timeouts and the transient errors of Azure SQL
+func (sqlserverDialect) Retryable(err error) bool {
+	var mssqlErr mssql.Error
+	if !errors.As(err, &mssqlErr) {
+		return false
+	}
+
+	switch mssqlErr.Number {
+	case 1205, 1222, 40197, 40501, 40613, 49918, 49919, 49920:
+		return true
+	}
+
+	return false
+}
+
+// RolledBack matches deadlocks, which roll back the transaction of the victim
+func (sqlserverDialect) RolledBack(err error) bool {
+	var mssqlErr mssql.Error
+	return errors.As(err, &mssqlErr) && mssqlErr.Number == 1205
+}
+
+func (sqlserverDialect) BuildDSN(host string, port int, user, password, database string) string {
+	u := url.URL{Scheme: "sqlserver", User: userInfo(user, password), Host: hostPort(host, port), RawQuery: url.Values{"database": {database}}.Encode()}
+	return u.String()
+}
+
+func (sqlserverDialect) Quote(identifier string) string {
+	return "[" + strings.ReplaceAll(identifier, "]", "]]") + "]"
+}
+
+func (sqlserverDialect) QuoteLiteral(value string) string { return quoteLiteral(value) }
+
+func (sqlserverDialect) VersionQuery() string {
+	return "SELECT CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128))"
+}
+
+func (d sqlserverDialect) TablesQuery(database, schema, _ string) string {
+	// SQL Server uses dbo as the default schema instead of public
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	return fmt.Sprintf("SELECT t.name FROM sys.tables t JOIN sys.schemas s ON t.schema_id = s.schema_id WHERE s.name = %s AND DB_NAME() = %s", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d sqlserverDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME = %s AND TABLE_CATALOG = %s ORDER BY ORDINAL_POSITION", d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d sqlserverDialect) ColumnDetailsQuery(database, table string) string {
+	// the maximum length of varchar(max) and nvarchar(max) is -1, comments are MS_Description extended properties
+	return fmt.Sprintf(`SELECT c.COLUMN_NAME, c.DATA_TYPE, c.IS_NULLABLE, c.COLUMN_DEFAULT, c.CHARACTER_MAXIMUM_LENGTH, c.NUMERIC_PRECISION, c.NUMERIC_SCALE,
+CASE WHEN COLUMNPROPERTY(t.id, c.COLUMN_NAME, 'IsIdentity') = 1 THEN 'YES' ELSE 'NO' END, CAST(p.value AS nvarchar(max)), c.ORDINAL_POSITION
+FROM INFORMATION_SCHEMA.COLUMNS c
+CROSS APPLY (SELECT OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)) AS id) t
+LEFT JOIN sys.extended_properties p ON p.class = 1 AND p.major_id = t.id AND p.minor_id = COLUMNPROPERTY(t.id, c.COLUMN_NAME, 'ColumnId') AND p.name = 'MS_Description'
+WHERE c.TABLE_NAME = %s AND c.TABLE_SCHEMA = SCHEMA_NAME() AND c.TABLE_CATALOG = %s ORDER BY c.ORDINAL_POSITION`, d.QuoteLiteral(table), d.QuoteLiteral(database))
+}
+
+func (d sqlserverDialect) SchemaQuery(database, schema string) string {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	return fmt.Sprintf("SELECT TABLE_NAME, COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT, ORDINAL_POSITION FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = %s AND TABLE_CATALOG = %s ORDER BY TABLE_NAME, ORDINAL_POSITION", d.QuoteLiteral(schema), d.QuoteLiteral(database))
+}
+
+func (d sqlserverDialect) IndexesQuery(_, table string) (string, error) {
+	// included columns are stored in the index but are not part of its key
+	return fmt.Sprintf("SELECT i.name, c.name, CASE WHEN i.is_unique = 1 THEN 'YES' ELSE 'NO' END, i.type_desc FROM sys.indexes i JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id WHERE i.object_id = OBJECT_ID(%s) AND ic.is_included_column = 0 ORDER BY i.name, ic.key_ordinal", d.QuoteLiteral(table)), nil
+}
+
+func (d sqlserverDialect) ForeignKeysQuery(_, schema string) (string, error) {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	return fmt.Sprintf(`SELECT fk.name, t.name, c.name,
+	CASE WHEN rs.schema_id = s.schema_id THEN rt.name ELSE rs.name + '.' + rt.name END, rc.name,
+	fk.delete_referential_action_desc, fk.update_referential_action_desc
+FROM sys.foreign_keys fk
+JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+JOIN sys.tables t ON t.object_id = fk.parent_object_id
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+JOIN sys.columns c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id
+JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+JOIN sys.schemas rs ON rs.schema_id = rt.schema_id
+JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE s.name = %s
+ORDER BY t.name, fk.name, fkc.constraint_column_id`, d.QuoteLiteral(schema)), nil
+}
+
+func (d sqlserverDialect) PrimaryKeyQuery(_, table string) (string, error) {
+	return fmt.Sprintf("SELECT c.name FROM sys.indexes i JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id WHERE i.object_id = OBJECT_ID(%s) AND i.is_primary_key = 1 ORDER BY ic.key_ordinal", d.QuoteLiteral(table)), nil
+}
+
+func (d sqlserverDialect) ConstraintsQuery(_, table string) (string, error) {
+	return fmt.Sprintf(`SELECT name, type, column_name, definition FROM (
+	SELECT cc.name, 'CHECK' AS type, c.name AS column_name, 'CHECK ' + cc.definition AS definition, 0 AS position
+	FROM sys.check_constraints cc
+	LEFT JOIN sys.columns c ON c.object_id = cc.parent_object_id AND c.column_id = cc.parent_column_id
+	WHERE cc.parent_object_id = OBJECT_ID(%[1]s)
+	UNION ALL
+	SELECT kc.name, 'UNIQUE', c.name, NULL, ic.key_ordinal
+	FROM sys.key_constraints kc
+	JOIN sys.index_columns ic ON ic.object_id = kc.parent_object_id AND ic.index_id = kc.unique_index_id
+	JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+	WHERE kc.type = 'UQ' AND kc.parent_object_id = OBJECT_ID(%[1]s)
+) constraints
+ORDER BY name, position`, d.QuoteLiteral(table)), nil
+}
+
+func (d sqlserverDialect) ViewsQuery(_, schema string) (string, error) {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	// INFORMATION_SCHEMA.VIEWS cuts definitions off at 4000 characters, sys.sql_modules has all of it
+	return fmt.Sprintf("SELECT v.name, m.definition FROM sys.views v JOIN sys.schemas s ON s.schema_id = v.schema_id JOIN sys.sql_modules m ON m.object_id = v.object_id WHERE s.name = %s ORDER BY v.name", d.QuoteLiteral(schema)), nil
+}
+
+func (d sqlserverDialect) TriggersQuery(_, table string) (string, error) {
+	return fmt.Sprintf("SELECT tr.name, CASE WHEN tr.is_instead_of_trigger = 1 THEN 'INSTEAD OF' ELSE 'AFTER' END, te.type_desc, m.definition FROM sys.triggers tr JOIN sys.trigger_events te ON te.object_id = tr.object_id JOIN sys.sql_modules m ON m.object_id = tr.object_id WHERE tr.parent_id = OBJECT_ID(%s) ORDER BY tr.name, te.type", d.QuoteLiteral(table)), nil
+}
+
+func (d sqlserverDialect) RoutinesQuery(_, schema string) (string, error) {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	// the return value of a scalar function is its parameter 0, table functions return TABLE
+	return fmt.Sprintf(`SELECT o.name, CASE WHEN o.type IN ('P', 'PC') THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+	COALESCE((SELECT STRING_AGG(p.name + ' ' + TYPE_NAME(p.user_type_id), ', ') WITHIN GROUP (ORDER BY p.parameter_id) FROM sys.parameters p WHERE p.object_id = o.object_id AND p.parameter_id > 0), ''),
+	CASE WHEN o.type IN ('IF', 'TF', 'FT') THEN 'TABLE' ELSE (SELECT TYPE_NAME(p.user_type_id) FROM sys.parameters p WHERE p.object_id = o.object_id AND p.parameter_id = 0) END,
+	CASE WHEN o.type IN ('PC', 'FS', 'FT') THEN 'CLR' ELSE 'SQL' END
+FROM sys.objects o
+JOIN sys.schemas s ON s.schema_id = o.schema_id
+WHERE o.type IN ('P', 'PC', 'FN', 'IF', 'TF', 'FS', 'FT') AND s.name = %s
+ORDER BY o.name`, d.QuoteLiteral(schema)), nil
+}
+
+func (d sqlserverDialect) SequencesQuery(_, schema string) (string, error) {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	// the values are sql_variant, a sequence that was never used reports its start value
+	return fmt.Sprintf(`SELECT sq.name, TYPE_NAME(sq.user_type_id),
+	CASE WHEN sq.last_used_value IS NOT NULL THEN CAST(sq.current_value AS bigint) END,
+	CAST(sq.increment AS bigint), CAST(sq.minimum_value AS bigint), CAST(sq.maximum_value AS bigint), ''
+FROM sys.sequences sq
+JOIN sys.schemas s ON s.schema_id = sq.schema_id
+WHERE s.name = %s
+ORDER BY sq.name`, d.QuoteLiteral(schema)), nil
+}
+
+func (sqlserverDialect) SchemasQuery(_ string) (string, error) {
+	// every fixed database role has a schema of its own, e.g. db_owner
+	return "SELECT s.name, p.name FROM sys.schemas s JOIN sys.database_principals p ON p.principal_id = s.principal_id WHERE s.name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') AND s.name NOT LIKE 'db[_]%' ORDER BY s.name", nil
+}
+
+func (sqlserverDialect) DatabasesQuery() (string, error) {
+	// the first four databases are master, tempdb, model and msdb
+	return "SELECT name, SUSER_SNAME(owner_sid) FROM sys.databases WHERE database_id > 4 ORDER BY name", nil
+}
+
+func (d sqlserverDialect) RowEstimatesQuery(_, schema string) (string, error) {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	// the rows of a table are in its heap (index 0) or clustered index (index 1)
+	return fmt.Sprintf("SELECT t.name, SUM(p.rows) FROM sys.tables t JOIN sys.schemas s ON s.schema_id = t.schema_id JOIN sys.partitions p ON p.object_id = t.object_id AND p.index_id IN (0, 1) WHERE s.name = %s GROUP BY t.name ORDER BY t.name", d.QuoteLiteral(schema)), nil
+}
+
+func (d sqlserverDialect) TableSizesQuery(_, schema string) (string, error) {
+	if schema == "public" {
+		schema = "dbo"
+	}
+
+	// pages are 8 KB, the rows are in the heap or clustered index and type 1 is in-row data, the others are LOB and
+	// row overflow pages
+	return fmt.Sprintf(`SELECT t.name,
+	SUM(CASE WHEN p.index_id IN (0, 1) AND a.type = 1 THEN a.used_pages ELSE 0 END) * 8192,
+	SUM(CASE WHEN p.index_id > 1 AND a.type = 1 THEN a.used_pages ELSE 0 END) * 8192,
+	SUM(CASE WHEN a.type <> 1 THEN a.used_pages ELSE 0 END) * 8192,
+	SUM(a.used_pages) * 8192
+FROM sys.tables t
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+JOIN sys.partitions p ON p.object_id = t.object_id
+JOIN sys.allocation_units a ON a.container_id = p.partition_id
+WHERE s.name = %s
+GROUP BY t.name
+ORDER BY 5 DESC, t.name`, d.QuoteLiteral(schema)), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// dialFunc dials a network address, matching the dial hooks of the database drivers
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Connect to the database through an SSH jump host
+func (m *Sql) WithSSHTunnel(
+	// Address of the SSH host, the port defaults to 22
+	host string,
+	// User to authenticate as on the SSH host
+	user string,
+	// Private key in PEM format
+	key *dagger.Secret,
+	// Public key of the SSH host in authorized_keys format, the host key is not verified when omitted
+	// +optional
+	hostKey string,
+) *Sql {
+	m.SSHHost = host
+	m.SSHUser = user
+	m.SSHKey = key
+	m.SSHHostKey = hostKey
+	return m
+}
+
+// sshDialer connects to the SSH host and returns a dialer that tunnels through it, or nil when no tunnel is configured
+func (m *Sql) sshDialer(ctx context.Context) (dialFunc, error) {
+	if m.SSHHost == "" {
+		return nil, nil
+	}
+
+	key, err := m.SSHKey.Plaintext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plaintext SSH key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SSH key: %w", err)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if m.SSHHostKey != "" {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(m.SSHHostKey))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing SSH host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(pub)
+	}
+
+	addr := m.SSHHost
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SSH host: %w", err)
+	}
+	// the handshake does not take a context, so closing the connection stops it when ctx ends
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            m.SSHUser,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+	})
+	if !stop() && err == nil {
+		c.Close()
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error connecting to SSH host: %w", err)
+	}
+
+	// the client stays open with the connection pool and is closed with it
+	m.closeSSH()
+	m.sshClient = ssh.NewClient(c, chans, reqs)
+
+	return m.sshClient.DialContext, nil
+}
+
+// closeSSH closes the client of the SSH tunnel, if one is open
+func (m *Sql) closeSSH() error {
+	if m.sshClient == nil {
+		return nil
+	}
+
+	err := m.sshClient.Close()
+	m.sshClient = nil
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// TableSize represents the storage a table and its indexes take up
+type TableSize struct {
+	Table string
+	// DataBytes is the size of the rows of the table
+	DataBytes int
+	// IndexBytes is the size of the indexes of the table
+	IndexBytes int
+	// ToastBytes is the size of values stored out of line, e.g. postgres toast or SQL Server LOB pages
+	ToastBytes int
+	// TotalBytes is the size of the table with everything that belongs to it
+	TotalBytes int
+}
+
+// tableSizer is implemented by dialects that can report the storage of their tables
+type tableSizer interface {
+	// TableSizesQuery lists the name, data, index, toast and total bytes of the tables in a schema, largest first
+	TableSizesQuery(database, schema string) (string, error)
+}
+
+// Report the data, index and toast sizes of the tables in a schema, largest first
+func (m *Sql) TableSizes(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+) ([]*TableSize, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	s, ok := d.(tableSizer)
+	if !ok {
+		return nil, fmt.Errorf("table sizes are not supported for %s", d.Name())
+	}
+	query, err := s.TableSizesQuery(database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying table sizes: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := []*TableSize{}
+	for rows.Next() {
+		size := &TableSize{}
+		if err := rows.Scan(&size.Table, &size.DataBytes, &size.IndexBytes, &size.ToastBytes, &size.TotalBytes); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		sizes = append(sizes, size)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return sizes, nil
+}
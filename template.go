@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Render a SQL script as a Go text/template with variables from a JSON object, then execute its statements in order.
+// Use {{ ident .table }} and {{ literal .name }} to quote values instead of interpolating them raw.
+func (m *Sql) RunTemplate(
+	ctx context.Context,
+	file *dagger.File,
+	// JSON object of the variables available to the template
+	// +default="{}"
+	varsJSON string,
+	// Show the plan of every statement instead of running them
+	// +optional
+	dryRun bool,
+) (*ExecResult, error) {
+	if m.Duckdb {
+		return nil, fmt.Errorf("templates are not supported for duckdb")
+	}
+
+	text, err := file.Contents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template: %w", err)
+	}
+
+	vars, err := templateVars(varsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	db, d, _, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	tmpl, err := template.New("script").Option("missingkey=error").Funcs(templateFuncs(d)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+
+	var script strings.Builder
+	if err := tmpl.Execute(&script, vars); err != nil {
+		return nil, fmt.Errorf("error rendering template: %w", err)
+	}
+	statements := splitStatements(script.String(), d.Engine())
+
+	if dryRun {
+		plan, err := m.dryRun(ctx, statements)
+		if err != nil {
+			return nil, err
+		}
+		return &ExecResult{Plan: plan}, nil
+	}
+
+	return m.runStatements(ctx, db, d, statements)
+}
+
+// templateVars decodes the variables of a template, keeping numbers as written as a float64 would round integers above 2^53
+func templateVars(varsJSON string) (map[string]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(varsJSON))
+	decoder.UseNumber()
+
+	var vars map[string]any
+	if err := decoder.Decode(&vars); err != nil {
+		return nil, fmt.Errorf("error parsing template variables: %w", err)
+	}
+
+	return vars, nil
+}
+
+// Query the database and render every row through a Go text/template, e.g. {{ .name }}={{ .value }}, where the fields
+// are the columns of the row. Use {{ json .column }} for JSON values and ident, literal and list to quote SQL.
+func (m *Sql) QueryWithTemplate(
+	ctx context.Context,
+	query string,
+	// Template rendered once per row, include a line break to put rows on separate lines
+	tmpl string,
+) (string, error) {
+	if m.Duckdb {
+		return "", fmt.Errorf("templates are not supported for duckdb")
+	}
+
+	_, d, _, err := m.connect(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	funcs := templateFuncs(d)
+	// json encodes a value, NULL is null
+	funcs["json"] = func(value any) (string, error) {
+		b, err := json.Marshal(value)
+		return string(b), err
+	}
+	t, err := template.New("row").Option("missingkey=error").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
+
+	var b strings.Builder
+	if _, err := m.stream(ctx, query, queryOptions{}, &templateWriter{w: &b, tmpl: t}); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// templateWriter renders every row through a template with the values of the row keyed by column name, numbers and
+// booleans keep their type and NULL is nil
+type templateWriter struct {
+	w       io.Writer
+	tmpl    *template.Template
+	columns []*ResultColumn
+}
+
+func (t *templateWriter) header(columns []*sql.ColumnType) error {
+	t.columns = resultColumns(columns)
+	return nil
+}
+
+func (t *templateWriter) row(values []any) error {
+	data := make(map[string]any, len(values))
+	for i, cell := range typedCells(t.columns, values) {
+		data[t.columns[i].Name] = cell.jsonValue()
+	}
+
+	if err := t.tmpl.Execute(t.w, data); err != nil {
+		return fmt.Errorf("error rendering template: %w", err)
+	}
+
+	return nil
+}
+
+func (t *templateWriter) flush() error { return nil }
+
+// templateFuncs returns the helpers that quote template values for the dialect
+func templateFuncs(d dialect) template.FuncMap {
+	return template.FuncMap{
+		// ident quotes an identifier such as a table or column name
+		"ident": func(value any) string {
+			return d.Quote(fmt.Sprint(value))
+		},
+		// literal quotes a string, number or boolean as a SQL value, nil is NULL
+		"literal": func(value any) string {
+			return templateLiteral(d, value)
+		},
+		// list quotes every element of an array as a literal and joins them with commas, e.g. for IN (...)
+		"list": func(values []any) string {
+			quoted := make([]string, len(values))
+			for i, value := range values {
+				quoted[i] = templateLiteral(d, value)
+			}
+			return strings.Join(quoted, ", ")
+		},
+	}
+}
+
+// templateLiteral renders a JSON value as a SQL literal of the dialect
+func templateLiteral(d dialect, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		// sqlserver and oracle have no boolean literals
+		if d.Engine() == "sqlserver" || d.Engine() == "oracle" {
+			if v {
+				return "1"
+			}
+			return "0"
+		}
+		return strings.ToUpper(strconv.FormatBool(v))
+	case json.Number:
+		return v.String()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return d.QuoteLiteral(v)
+	}
+
+	return d.QuoteLiteral(fmt.Sprint(value))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     dialect
+		value any
+		want  string
+	}{
+		{name: "nil", d: postgresDialect{}, value: nil, want: "NULL"},
+		{name: "true", d: postgresDialect{}, value: true, want: "TRUE"},
+		{name: "false on sqlserver", d: sqlserverDialect{}, value: false, want: "0"},
+		{name: "true on oracle", d: oracleDialect{}, value: true, want: "1"},
+		{name: "float", d: postgresDialect{}, value: 1.5, want: "1.5"},
+		{name: "large float", d: postgresDialect{}, value: 1e21, want: "1000000000000000000000"},
+		{name: "json number", d: postgresDialect{}, value: json.Number("9007199254740993"), want: "9007199254740993"},
+		{name: "json decimal", d: postgresDialect{}, value: json.Number("12.50"), want: "12.50"},
+		{name: "int", d: postgresDialect{}, value: 42, want: "42"},
+		{name: "int64", d: postgresDialect{}, value: int64(-9007199254740993), want: "-9007199254740993"},
+		{name: "uint64", d: postgresDialect{}, value: uint64(18446744073709551615), want: "18446744073709551615"},
+		{name: "string", d: postgresDialect{}, value: "it's", want: "'it''s'"},
+		{name: "backslash on postgres", d: postgresDialect{}, value: `a\`, want: `'a\'`},
+		{name: "backslash on mysql", d: mysqlDialect{}, value: `a\`, want: `'a\\'`},
+		{name: "other value", d: postgresDialect{}, value: []string{"a"}, want: "'[a]'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := templateLiteral(tt.d, tt.value); got != tt.want {
+				t.Errorf("templateLiteral(%#v) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	vars, err := templateVars(`{"table": "users", "id": 9007199254740993, "names": ["a", "b'c"], "active": true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := template.Must(template.New("script").Funcs(templateFuncs(postgresDialect{})).Parse(
+		`SELECT * FROM {{ ident .table }} WHERE id = {{ literal .id }} AND name IN ({{ list .names }}) AND active = {{ literal .active }}`,
+	))
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `SELECT * FROM "users" WHERE id = 9007199254740993 AND name IN ('a', 'b''c') AND active = TRUE`
+	if got := b.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
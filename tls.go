@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"dagger/sql/internal/dagger"
+	"errors"
+	"fmt"
+)
+
+// Use TLS for postgres and mysql connections with certificates from secrets
+func (m *Sql) WithTLS(
+	// CA certificate in PEM format used to verify the server
+	// +optional
+	caCert *dagger.Secret,
+	// Client certificate in PEM format
+	// +optional
+	clientCert *dagger.Secret,
+	// Client key in PEM format
+	// +optional
+	clientKey *dagger.Secret,
+	// TLS mode, one of require, verify-ca or verify-full
+	// +default="verify-full"
+	mode string,
+) *Sql {
+	m.TLSCA = caCert
+	m.TLSCert = clientCert
+	m.TLSKey = clientKey
+	m.TLSMode = mode
+	return m
+}
+
+// tlsConfig returns the TLS configuration for the connection, or nil when TLS is not configured
+func (m *Sql) tlsConfig(ctx context.Context, serverName string) (*tls.Config, error) {
+	if m.TLSMode == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{ServerName: serverName}
+	if m.TLSCA != nil {
+		ca, err := m.TLSCA.Plaintext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting plaintext CA certificate: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(ca)) {
+			return nil, fmt.Errorf("error parsing CA certificate: no certificates found")
+		}
+	}
+
+	if m.TLSCert != nil || m.TLSKey != nil {
+		if m.TLSCert == nil || m.TLSKey == nil {
+			return nil, fmt.Errorf("both a client certificate and client key are required")
+		}
+
+		cert, err := m.TLSCert.Plaintext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting plaintext client certificate: %w", err)
+		}
+		key, err := m.TLSKey.Plaintext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting plaintext client key: %w", err)
+		}
+
+		pair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	switch m.TLSMode {
+	case "require":
+		cfg.InsecureSkipVerify = true
+	case "verify-ca":
+		// verify the certificate chain but not the host name
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyChain(rawCerts, cfg.RootCAs)
+		}
+	case "verify-full":
+	default:
+		return nil, fmt.Errorf("unsupported TLS mode: %s", m.TLSMode)
+	}
+
+	return cfg, nil
+}
+
+// verifyChain verifies the certificates presented by the server against the CA pool
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server presented no certificates")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("error parsing server certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Trigger represents a trigger on a table
+type Trigger struct {
+	Name string
+	// Timing is BEFORE, AFTER or INSTEAD OF
+	Timing string
+	// Events are the statements that fire the trigger, e.g. INSERT, UPDATE or DELETE
+	Events []string
+	// Action is the function the trigger runs for postgres and the body of the trigger elsewhere
+	Action string
+}
+
+// triggerLister is implemented by dialects that can list the triggers of a table
+type triggerLister interface {
+	// TriggersQuery lists the trigger name, timing, event and action of every event of the triggers on a table, ordered
+	// by trigger, an event can name several events separated by OR
+	TriggersQuery(database, table string) (string, error)
+}
+
+// List the triggers on a table with their timing, events and the function or body they run
+func (m *Sql) ListTriggers(ctx context.Context, table string) ([]*Trigger, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(triggerLister)
+	if !ok {
+		return nil, fmt.Errorf("listing triggers is not supported for %s", d.Name())
+	}
+	query, err := l.TriggersQuery(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying triggers: %w", err)
+	}
+	defer rows.Close()
+
+	triggers := []*Trigger{}
+	var trigger *Trigger
+	for rows.Next() {
+		var name, timing, event, action string
+		if err := rows.Scan(&name, &timing, &event, &action); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		// rows are ordered by trigger, so a new name starts the next trigger
+		if trigger == nil || trigger.Name != name {
+			trigger = &Trigger{Name: name, Timing: strings.ToUpper(timing), Events: []string{}, Action: action}
+			triggers = append(triggers, trigger)
+		}
+		for _, e := range strings.Split(event, " OR ") {
+			trigger.Events = append(trigger.Events, strings.ToUpper(strings.TrimSpace(e)))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return triggers, nil
+}
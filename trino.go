@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	_ "github.com/trinodb/trino-go-client/trino"
+)
+
+type trinoDialect struct{}
+
+func (trinoDialect) Name() string   { return "trino" }
+func (trinoDialect) Engine() string { return "trino" }
+
+// Detect accepts trino+http:// and trino+https:// to choose the protocol, trino:// uses https when it has a password
+func (trinoDialect) Detect(conn string) bool {
+	return strings.HasPrefix(conn, "trino://") || strings.HasPrefix(conn, "trino+http://") || strings.HasPrefix(conn, "trino+https://")
+}
+
+func (trinoDialect) Open(_ context.Context, _ *Sql, dsn string) (*sql.DB, string, error) {
+	u, err := trinoURL(dsn)
+	if err != nil {
+		return nil, "", err
+	}
+
+	db, err := sql.Open("trino", u.String())
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	return db, u.Query().Get("catalog"), nil
+}
+
+// trinoURL returns the http(s) URL the trino driver connects to, the catalog and schema are query parameters and trino
+// only accepts passwords over https
+func trinoURL(dsn string) (*url.URL, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing connection string: %w", err)
+	}
+
+	switch u.Scheme {
+	case "trino+http":
+		u.Scheme = "http"
+	case "trino+https":
+		u.Scheme = "https"
+	default:
+		u.Scheme = "http"
+		if _, ok := u.User.Password(); ok {
+			u.Scheme = "https"
+		}
+	}
+
+	return u, nil
+}
+
+// BuildDSN uses the database as the trino catalog
+func (trinoDialect) BuildDSN(host string, port int, user, password, database string) string {
+	u := url.URL{Scheme: "trino", User: userInfo(user, password), Host: hostPort(host, port), RawQuery: url.Values{"catalog": {database}}.Encode()}
+	return u.String()
+}
+
+func (trinoDialect) ExplainQuery(statement string, analyze bool, format string) (string, error) {
+	switch {
+	case analyze && format == "text":
+		return "EXPLAIN ANALYZE " + statement, nil
+	case analyze:
+		return "", fmt.Errorf("trino only supports explain analyze with the text format")
+	case format == "text", format == "json":
+		return fmt.Sprintf("EXPLAIN (FORMAT %s) %s", strings.ToUpper(format), statement), nil
+	}
+
+	return "", fmt.Errorf("unsupported explain format for trino: %s", format)
+}
+
+func (trinoDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (trinoDialect) QuoteLiteral(value string) string { return quoteLiteral(value) }
+
+func (trinoDialect) VersionQuery() string {
+	return "SELECT node_version FROM system.runtime.nodes WHERE coordinator"
+}
+
+func (d trinoDialect) TablesQuery(database, schema, catalog string) string {
+	// each catalog has its own information schema
+	if catalog == "" {
+		catalog = database
+	}
+
+	return fmt.Sprintf("SELECT table_name FROM %s.information_schema.tables WHERE table_schema = %s AND table_type = 'BASE TABLE'", d.Quote(catalog), d.QuoteLiteral(schema))
+}
+
+func (d trinoDialect) ColumnsQuery(database, table string) string {
+	return fmt.Sprintf("SELECT column_name FROM %s.information_schema.columns WHERE table_name = %s ORDER BY ordinal_position", d.Quote(database), d.QuoteLiteral(table))
+}
+
+func (d trinoDialect) ColumnDetailsQuery(database, table string) string {
+	// the length and precision of a type such as varchar(20) or decimal(10, 2) are part of its name
+	return fmt.Sprintf("SELECT column_name, data_type, is_nullable, column_default, NULL, NULL, NULL, 'NO', NULL, ordinal_position FROM %s.information_schema.columns WHERE table_name = %s ORDER BY ordinal_position", d.Quote(database), d.QuoteLiteral(table))
+}
+
+func (d trinoDialect) SchemaQuery(database, schema string) string {
+	return fmt.Sprintf("SELECT table_name, column_name, data_type, is_nullable, column_default, ordinal_position FROM %s.information_schema.columns WHERE table_schema = %s ORDER BY table_name, ordinal_position", d.Quote(database), d.QuoteLiteral(schema))
+}
+
+func (d trinoDialect) ViewsQuery(database, schema string) (string, error) {
+	return fmt.Sprintf("SELECT table_name, view_definition FROM %s.information_schema.views WHERE table_schema = %s ORDER BY table_name", d.Quote(database), d.QuoteLiteral(schema)), nil
+}
+
+func (d trinoDialect) SchemasQuery(database string) (string, error) {
+	return fmt.Sprintf("SELECT schema_name, NULL FROM %s.information_schema.schemata WHERE schema_name <> 'information_schema' ORDER BY schema_name", d.Quote(database)), nil
+}
+
+func (trinoDialect) DatabasesQuery() (string, error) {
+	// the databases of trino are its catalogs, which have no owners
+	return "SELECT catalog_name, NULL FROM system.metadata.catalogs WHERE catalog_name <> 'system' ORDER BY catalog_name", nil
+}
+
+func (d trinoDialect) TableDDLQuery(_, table string) (string, error) {
+	return "SHOW CREATE TABLE " + quoteQualified(d, table), nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedResult represents query results that keep the type of every value and distinguish NULL from empty values
+type TypedResult struct {
+	Columns []*ResultColumn
+	Rows    []*ResultRow
+}
+
+// ResultColumn represents a column of a typed result
+type ResultColumn struct {
+	Name string
+	// DatabaseType is the type name reported by the database, e.g. VARCHAR or INT8
+	DatabaseType string
+}
+
+// ResultRow represents a row of a typed result
+type ResultRow struct {
+	Cells []*ResultCell
+}
+
+// ResultCell represents a value of a typed result
+type ResultCell struct {
+	// Value is the text of the value, empty for NULL, bytes are base64 encoded and times use RFC 3339
+	Value string
+	// Type is string, integer, decimal, float, boolean, time or bytes
+	Type string
+	Null bool
+}
+
+// Query the database and return the results with the type of every column and value
+func (m *Sql) RunQueryTyped(
+	ctx context.Context,
+	query string,
+	// Cancel the query on the server after this many seconds, overriding the statement timeout
+	// +optional
+	timeoutSeconds int,
+) (*TypedResult, error) {
+	result := &TypedResult{}
+	if _, err := m.stream(ctx, query, queryOptions{timeoutSeconds: timeoutSeconds}, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Encode the results as a JSON array of objects keyed by column name, with numbers, booleans and nulls as JSON values
+func (r *TypedResult) Json() (string, error) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range r.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		object, err := jsonObject(r.Columns, row.Cells)
+		if err != nil {
+			return "", err
+		}
+		b.Write(object)
+	}
+	b.WriteString("]")
+
+	return b.String(), nil
+}
+
+func (r *TypedResult) header(columns []*sql.ColumnType) error {
+	r.Columns = resultColumns(columns)
+	return nil
+}
+
+func (r *TypedResult) row(values []any) error {
+	r.Rows = append(r.Rows, &ResultRow{Cells: typedCells(r.Columns, values)})
+	return nil
+}
+
+func (r *TypedResult) flush() error { return nil }
+
+// resultColumns returns the name and database type of every column
+func resultColumns(columns []*sql.ColumnType) []*ResultColumn {
+	result := make([]*ResultColumn, len(columns))
+	for i, column := range columns {
+		result[i] = &ResultColumn{Name: column.Name(), DatabaseType: column.DatabaseTypeName()}
+	}
+
+	return result
+}
+
+// typedCells converts the values of a row into cells
+func typedCells(columns []*ResultColumn, values []any) []*ResultCell {
+	cells := make([]*ResultCell, len(values))
+	for i, value := range values {
+		cells[i] = typedCell(value, columns[i].DatabaseType)
+	}
+
+	return cells
+}
+
+// jsonObject encodes the cells of a row as a JSON object with the keys in column order
+func jsonObject(columns []*ResultColumn, cells []*ResultCell) ([]byte, error) {
+	b := []byte("{")
+	for i, cell := range cells {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		key, err := json.Marshal(columns[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(cell.jsonValue())
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s: %w", columns[i].Name, err)
+		}
+		b = append(append(append(b, key...), ':'), value...)
+	}
+
+	return append(b, '}'), nil
+}
+
+// jsonValue returns the value to encode for a cell, numbers keep their exact digits
+func (c *ResultCell) jsonValue() any {
+	if c.Null {
+		return nil
+	}
+
+	switch c.Type {
+	case "integer", "decimal", "float":
+		// NaN and infinity have no JSON number
+		if _, err := strconv.ParseFloat(c.Value, 64); err == nil && json.Valid([]byte(c.Value)) {
+			return json.Number(c.Value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(c.Value); err == nil {
+			return b
+		}
+	}
+
+	return c.Value
+}
+
+// typedCell converts a scanned value into a cell, using the database type for drivers that return text or bytes
+func typedCell(value any, databaseType string) *ResultCell {
+	kind := databaseKind(databaseType)
+
+	switch v := value.(type) {
+	case nil:
+		return &ResultCell{Type: kind, Null: true}
+	case int64:
+		return &ResultCell{Value: strconv.FormatInt(v, 10), Type: "integer"}
+	case float64:
+		return &ResultCell{Value: strconv.FormatFloat(v, 'f', -1, 64), Type: "float"}
+	case float32:
+		return &ResultCell{Value: strconv.FormatFloat(float64(v), 'f', -1, 32), Type: "float"}
+	case bool:
+		return &ResultCell{Value: strconv.FormatBool(v), Type: "boolean"}
+	case time.Time:
+		return &ResultCell{Value: v.Format(time.RFC3339Nano), Type: "time"}
+	case []byte:
+		if kind == "bytes" {
+			return &ResultCell{Value: base64.StdEncoding.EncodeToString(v), Type: kind}
+		}
+		return &ResultCell{Value: string(v), Type: kind}
+	case string:
+		return &ResultCell{Value: v, Type: kind}
+	}
+
+	return &ResultCell{Value: fmt.Sprintf("%v", value), Type: kind}
+}
+
+// databaseKind maps the type name reported by a database to the type of a cell
+func databaseKind(databaseType string) string {
+	switch strings.TrimPrefix(strings.ToUpper(databaseType), "UNSIGNED ") {
+	case "INT", "INT2", "INT4", "INT8", "INT64", "INTEGER", "SMALLINT", "BIGINT", "TINYINT", "MEDIUMINT", "YEAR":
+		return "integer"
+	case "DECIMAL", "NUMERIC", "NUMBER", "BIGNUMERIC":
+		return "decimal"
+	case "FLOAT", "FLOAT4", "FLOAT8", "FLOAT64", "DOUBLE", "REAL":
+		return "float"
+	case "BOOL", "BOOLEAN":
+		return "boolean"
+	case "DATE", "TIME", "TIMETZ", "TIMESTAMP", "TIMESTAMPTZ", "DATETIME", "DATETIME2", "DATETIMEOFFSET", "SMALLDATETIME":
+		return "time"
+	case "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "IMAGE", "BYTES", "RAW":
+		return "bytes"
+	}
+
+	return "string"
+}
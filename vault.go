@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"dagger/sql/internal/dagger"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// vaultResponse is the subset of a Vault API response used for dynamic database credentials
+type vaultResponse struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Data          struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// Fetch short-lived credentials from the Vault database secrets engine and use them for the connection
+func (m *Sql) WithVault(
+	ctx context.Context,
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	addr string,
+	// Role of the database secrets engine to generate credentials for
+	role string,
+	// Vault token used to request the credentials
+	token *dagger.Secret,
+	// Mount path of the database secrets engine
+	// +default="database"
+	mount string,
+) (*Sql, error) {
+	m.VaultAddr = strings.TrimSuffix(addr, "/")
+	m.VaultToken = token
+
+	resp, err := m.vaultRequest(ctx, http.MethodGet, fmt.Sprintf("/v1/%s/creds/%s", mount, role), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching database credentials from vault: %w", err)
+	}
+
+	m.VaultLease = resp.LeaseID
+	m.User = resp.Data.Username
+	m.Password = dag.SetSecret("vault-"+strings.ReplaceAll(resp.LeaseID, "/", "-"), resp.Data.Password)
+
+	return m, nil
+}
+
+// Renew the lease of the Vault credentials and return the new lease duration in seconds
+func (m *Sql) RenewVaultLease(
+	ctx context.Context,
+	// Requested lease extension in seconds, 0 uses the default of the role
+	// +optional
+	incrementSeconds int,
+) (int, error) {
+	if m.VaultLease == "" {
+		return 0, fmt.Errorf("no vault lease, use with-vault first")
+	}
+
+	body := map[string]any{"lease_id": m.VaultLease}
+	if incrementSeconds > 0 {
+		body["increment"] = incrementSeconds
+	}
+
+	resp, err := m.vaultRequest(ctx, http.MethodPut, "/v1/sys/leases/renew", body)
+	if err != nil {
+		return 0, fmt.Errorf("error renewing vault lease: %w", err)
+	}
+
+	return resp.LeaseDuration, nil
+}
+
+// Revoke the lease of the Vault credentials, call this when the pipeline no longer needs the database
+func (m *Sql) RevokeVaultLease(ctx context.Context) error {
+	if m.VaultLease == "" {
+		return fmt.Errorf("no vault lease, use with-vault first")
+	}
+
+	if _, err := m.vaultRequest(ctx, http.MethodPut, "/v1/sys/leases/revoke", map[string]any{"lease_id": m.VaultLease}); err != nil {
+		return fmt.Errorf("error revoking vault lease: %w", err)
+	}
+
+	return nil
+}
+
+// vaultRequest calls the Vault API with the configured token
+func (m *Sql) vaultRequest(ctx context.Context, method, path string, body any) (*vaultResponse, error) {
+	token, err := m.VaultToken.Plaintext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plaintext vault token: %w", err)
+	}
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.VaultAddr+path, &payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resp := &vaultResponse{}
+	if res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+			return nil, fmt.Errorf("error decoding vault response: %w", err)
+		}
+	}
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault returned %s: %s", res.Status, strings.Join(resp.Errors, ", "))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// ViewDefinition represents a view and the query that defines it
+type ViewDefinition struct {
+	Name string
+	// Definition is the SELECT of the view, sqlserver and sqlite return the whole CREATE VIEW statement
+	Definition string
+}
+
+// viewLister is implemented by dialects that can list the views of a schema
+type viewLister interface {
+	// ViewsQuery lists the name and definition of the views in a schema, ordered by name
+	ViewsQuery(database, schema string) (string, error)
+}
+
+// List the views of a schema with their definitions
+func (m *Sql) ListViews(
+	ctx context.Context,
+	// +default="public"
+	schema string,
+) ([]*ViewDefinition, error) {
+	db, d, database, err := m.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database connection: %w", err)
+	}
+
+	l, ok := d.(viewLister)
+	if !ok {
+		return nil, fmt.Errorf("listing views is not supported for %s", d.Name())
+	}
+	query, err := l.ViewsQuery(database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.statementContext(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []*ViewDefinition{}
+	for rows.Next() {
+		view := &ViewDefinition{}
+		if err := rows.Scan(&view.Name, &view.Definition); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return views, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"dagger/sql/internal/dagger"
+	"database/sql"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSheet is the name of the worksheet when none is given
+const xlsxSheet = "Results"
+
+// xlsxTimeFormat is the number format of time cells
+const xlsxTimeFormat = "yyyy-mm-dd hh:mm:ss"
+
+// Query the database and return the results as an Excel workbook, with a styled header row and numbers, booleans and
+// times written as typed cells
+func (m *Sql) QueryToXlsx(
+	ctx context.Context,
+	query string,
+	// Name of the worksheet
+	// +default="Results"
+	sheetName string,
+	// Gzip the file, adding .gz to its name
+	// +optional
+	compress bool,
+) (*dagger.File, error) {
+	return m.queryToFile(ctx, query, "xlsx", compress, queryOptions{}, func(w io.Writer, _ formatOptions) rowWriter {
+		return &xlsxWriter{w: w, sheet: sheetName}
+	})
+}
+
+// xlsxWriter streams the rows into a worksheet and writes the workbook once every row is read
+type xlsxWriter struct {
+	w       io.Writer
+	sheet   string
+	columns []*ResultColumn
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	time    int
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, _ formatOptions) rowWriter {
+	return &xlsxWriter{w: w, sheet: xlsxSheet}
+}
+
+func (x *xlsxWriter) header(columns []*sql.ColumnType) error {
+	x.columns = resultColumns(columns)
+	x.file = excelize.NewFile()
+	if err := x.file.SetSheetName(x.file.GetSheetName(0), x.sheet); err != nil {
+		return err
+	}
+
+	stream, err := x.file.NewStreamWriter(x.sheet)
+	if err != nil {
+		return err
+	}
+	x.stream = stream
+
+	header, err := x.file.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true},
+		Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#D9E1F2"}},
+		Border: []excelize.Border{{Type: "bottom", Color: "#000000", Style: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	timeFormat := xlsxTimeFormat
+	if x.time, err = x.file.NewStyle(&excelize.Style{CustomNumFmt: &timeFormat}); err != nil {
+		return err
+	}
+
+	// keep the header in view while scrolling
+	if err := x.stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	cells := make([]any, len(columns))
+	for i, name := range columnNames(columns) {
+		cells[i] = excelize.Cell{StyleID: header, Value: name}
+	}
+
+	return x.line(cells)
+}
+
+func (x *xlsxWriter) row(values []any) error {
+	cells := make([]any, len(values))
+	for i, value := range values {
+		cells[i] = x.cell(value, typedCell(value, x.columns[i].DatabaseType))
+	}
+
+	return x.line(cells)
+}
+
+// cell returns the value of a cell in the type excel should store it as
+func (x *xlsxWriter) cell(value any, cell *ResultCell) any {
+	if cell.Null {
+		return nil
+	}
+
+	switch cell.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(cell.Value, 10, 64); err == nil {
+			return n
+		}
+	case "decimal", "float":
+		if n, err := strconv.ParseFloat(cell.Value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(cell.Value); err == nil {
+			return b
+		}
+	case "time":
+		t, ok := value.(time.Time)
+		if !ok {
+			var err error
+			if t, err = parseTime(cell.Value); err != nil {
+				return cell.Value
+			}
+		}
+		return excelize.Cell{StyleID: x.time, Value: t.UTC()}
+	}
+
+	return cell.Value
+}
+
+func (x *xlsxWriter) line(cells []any) error {
+	x.rows++
+	name, err := excelize.CoordinatesToCellName(1, x.rows)
+	if err != nil {
+		return err
+	}
+
+	return x.stream.SetRow(name, cells)
+}
+
+func (x *xlsxWriter) flush() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+
+	return x.file.Write(x.w)
+}